@@ -3,13 +3,13 @@ package goclash
 // Achievement represents a Clash of Clans achievement.
 // Use AchievementIndex* constants to index into the Achievements slice.
 type Achievement struct {
-	Name           string `json:"name"`
-	Stars          int    `json:"stars"`
-	Value          int    `json:"value"`
-	Target         int    `json:"target"`
-	Info           string `json:"info"`
-	CompletionInfo string `json:"completionInfo"`
-	Village        string `json:"village"`
+	Name           string  `json:"name"`
+	Stars          int     `json:"stars"`
+	Value          int     `json:"value"`
+	Target         int     `json:"target"`
+	Info           string  `json:"info"`
+	CompletionInfo string  `json:"completionInfo"`
+	Village        Village `json:"village"`
 }
 
 // IndexedAchievement embeds Achievement and adds the index of the achievement in the Player.Achievements slice to it.
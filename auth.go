@@ -26,6 +26,7 @@ type APIKey struct {
 	Key         string   `json:"key"`
 	Scopes      []string `json:"scopes"`
 	CidrRanges  []string `json:"cidrRanges"`
+	limiter     *rateLimiter
 }
 
 // APIKeyIndex is used to determine which account and key to use for a given request.
@@ -0,0 +1,150 @@
+package goclash
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultMaxConcurrency = 32
+
+// Result pairs a tag with the value fetched for it, or the error fetching it failed with.
+type Result[T any] struct {
+	Index int // Index is the position of Tag in the tags passed to the bulk method.
+	Tag   Tag
+	Value *T
+	Err   error
+}
+
+// BulkResult holds a Result for every tag passed to a bulk method, in the original order of the tags.
+type BulkResult[T any] []Result[T]
+
+// Values returns the fetched values in the original order of the tags. Values of tags that failed to be fetched are nil.
+func (r BulkResult[T]) Values() []*T {
+	values := make([]*T, len(r))
+	for i, res := range r {
+		values[i] = res.Value
+	}
+	return values
+}
+
+// Err joins a *TagError for every tag that failed to be fetched, using errors.Join. It returns nil if all tags were fetched successfully.
+func (r BulkResult[T]) Err() error {
+	var errs []error
+	for _, res := range r {
+		if res.Err != nil {
+			errs = append(errs, &TagError{Tag: res.Tag, Err: res.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotFound returns the tags the API responded to with http.StatusNotFound. Retrying them is pointless.
+func (r BulkResult[T]) NotFound() []Tag {
+	var tags []Tag
+	for _, res := range r {
+		if res.Err != nil && isNotFound(res.Err) {
+			tags = append(tags, res.Tag)
+		}
+	}
+	return tags
+}
+
+// Failed returns the tags that failed to be fetched for any other reason than not being found, e.g. a timeout or maintenance. These tags may be retried.
+func (r BulkResult[T]) Failed() []Tag {
+	var tags []Tag
+	for _, res := range r {
+		if res.Err != nil && !isNotFound(res.Err) {
+			tags = append(tags, res.Tag)
+		}
+	}
+	return tags
+}
+
+// TagError is the error fetching a single tag of a bulk request failed with.
+type TagError struct {
+	Tag Tag
+	Err error
+}
+
+func (e *TagError) Error() string {
+	if e.NotFound() {
+		return e.Tag.String() + ": not found"
+	}
+	return e.Tag.String() + ": " + e.Err.Error()
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// NotFound reports whether the tag does not exist.
+func (e *TagError) NotFound() bool {
+	return isNotFound(e.Err)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// collect waits for all results of fetchEach, and returns them in the original order of the tags.
+func collect[T any](results <-chan Result[T], n int) BulkResult[T] {
+	bulk := make(BulkResult[T], n)
+	for res := range results {
+		bulk[res.Index] = res
+	}
+	return bulk
+}
+
+// fetchEach calls fetch for every tag, sending each result to the returned channel as soon as it arrives. The channel is buffered for all results, and closed once every tag has a result.
+//
+// The number of concurrent fetches is limited across the whole client (see WithMaxConcurrency). Once ctx is done, tags that were not fetched yet get a result with the context's error.
+func fetchEach[T any](ctx context.Context, h *Client, tags []Tag, fetch func(context.Context, Tag) (*T, error)) <-chan Result[T] {
+	results := make(chan Result[T], len(tags))
+	go func() {
+		var wg sync.WaitGroup
+		for i, tag := range tags {
+			select {
+			case h.sem <- struct{}{}:
+			case <-ctx.Done():
+				results <- Result[T]{Index: i, Tag: tag, Err: ctx.Err()}
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, tag Tag) {
+				defer func() {
+					<-h.sem
+					wg.Done()
+				}()
+				value, err := fetch(ctx, tag)
+				results <- Result[T]{Index: i, Tag: tag, Value: value, Err: err}
+			}(i, tag)
+		}
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// StreamPlayers fetches multiple players concurrently, like GetPlayers, but sends each result to the returned channel as soon as it arrives.
+// The channel is closed once all players have been fetched, and does not have to be drained. Cancel ctx to stop early.
+func (h *Client) StreamPlayers(ctx context.Context, tags ...Tag) <-chan Result[Player] {
+	return fetchEach(ctx, h, tags, h.GetPlayerCtx)
+}
+
+// StreamClans fetches multiple clans concurrently, like GetClans, but sends each result to the returned channel as soon as it arrives.
+// The channel is closed once all clans have been fetched, and does not have to be drained. Cancel ctx to stop early.
+func (h *Client) StreamClans(ctx context.Context, tags ...Tag) <-chan Result[Clan] {
+	return fetchEach(ctx, h, tags, h.GetClanCtx)
+}
+
+// FetchPlayers fetches multiple players concurrently, and returns the player or error of every tag. Use BulkResult.Failed to get the tags worth retrying.
+func (h *Client) FetchPlayers(ctx context.Context, tags ...Tag) BulkResult[Player] {
+	return collect(h.StreamPlayers(ctx, tags...), len(tags))
+}
+
+// FetchClans fetches multiple clans concurrently, and returns the clan or error of every tag. Use BulkResult.Failed to get the tags worth retrying.
+func (h *Client) FetchClans(ctx context.Context, tags ...Tag) BulkResult[Clan] {
+	return collect(h.StreamClans(ctx, tags...), len(tags))
+}
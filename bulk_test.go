@@ -0,0 +1,99 @@
+package goclash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamPlayersMaxConcurrency(t *testing.T) {
+	const limit = 2
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, `{"tag":"#2PP"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithMaxConcurrency(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := make([]Tag, 10)
+	for i := range tags {
+		tags[i] = TagFromID(uint32(i), 1)
+	}
+	seen := make(map[int]bool)
+	for res := range client.StreamPlayers(context.Background(), tags...) {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		if res.Tag != tags[res.Index] {
+			t.Fatalf("result %d has tag %s, want %s", res.Index, res.Tag, tags[res.Index])
+		}
+		seen[res.Index] = true
+	}
+	if len(seen) != len(tags) {
+		t.Fatalf("got %d results, want %d", len(seen), len(tags))
+	}
+	if maxInFlight > limit {
+		t.Fatalf("%d requests were in flight at once, want at most %d", maxInFlight, limit)
+	}
+}
+
+func TestFetchPlayersErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/players/#2QQ":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"reason":"notFound"}`)
+		case "/players/#2GG":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"reason":"unknownException"}`)
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+			fmt.Fprint(w, `{"tag":"#2PP"}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := client.FetchPlayers(context.Background(), "#2PP", "#2QQ", "#2GG")
+	if values := res.Values(); values[0] == nil || values[1] != nil || values[2] != nil {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if notFound := res.NotFound(); len(notFound) != 1 || notFound[0] != "#2QQ" {
+		t.Fatalf("NotFound() = %v, want [#2QQ]", notFound)
+	}
+	if failed := res.Failed(); len(failed) != 1 || failed[0] != "#2GG" {
+		t.Fatalf("Failed() = %v, want [#2GG]", failed)
+	}
+
+	var tagErr *TagError
+	if !errors.As(res.Err(), &tagErr) || tagErr.Tag != "#2QQ" || !tagErr.NotFound() {
+		t.Fatalf("Err() = %v, want *TagError for #2QQ first", res.Err())
+	}
+
+	if retried := client.FetchPlayers(context.Background(), res.Failed()...); len(retried) != 1 || retried[0].Tag != "#2GG" {
+		t.Fatalf("retrying Failed() returned %+v, want a result for #2GG", retried)
+	}
+}
@@ -1,37 +1,154 @@
 package goclash
 
 import (
-	"strconv"
+	"encoding/binary"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
-	cmap "github.com/orcaman/concurrent-map/v2"
 )
 
-// Cache is a simple but performant in-memory cache.
+// revalidationWindow is how long responses with an ETag or Last-Modified header are kept after expiring, so they can be revalidated using a conditional request.
+const revalidationWindow = 5 * time.Minute
+
+// Cache caches API responses in a CacheStore, which is a MemoryStore by default.
+//
+// Together with the response body, the ETag and Last-Modified headers are cached. Once a response expires, it is refreshed using a conditional request,
+// and a http.StatusNotModified response counts as a cache hit.
 type Cache struct {
-	enabled   bool
-	store     cmap.ConcurrentMap[string, *cachedValue]
-	cacheTime time.Duration
-	mu        sync.Mutex
+	enabled              bool
+	store                CacheStore
+	cacheTime            time.Duration
+	staleWhileRevalidate time.Duration
+	hits                 atomic.Uint64
+	misses               atomic.Uint64
+	mu                   sync.RWMutex
+}
+
+// CacheStats are statistics about a Cache.
+type CacheStats struct {
+	Hits      uint64 // Hits is the number of lookups that found a value.
+	Misses    uint64 // Misses is the number of lookups that found no value.
+	Evictions uint64 // Evictions is the number of values removed to stay within the limits of the store, not counting expired values.
+	Entries   int    // Entries is the number of stored values.
+	Bytes     int64  // Bytes is the total size of the stored keys and values.
+}
+
+// statsReporter is implemented by stores reporting Evictions, Entries and Bytes of CacheStats, like MemoryStore.
+type statsReporter interface {
+	Stats() CacheStats
 }
 
-type cachedValue struct {
-	data  []byte      // data is the cached value
-	timer *time.Timer // timer schedules removal of cached value
+// CacheStore is the storage backend of a Cache. Implementations must be safe for concurrent use.
+//
+// Caching is best effort, so implementations handle errors themselves, e.g. by treating a failed Get as a cache miss.
+type CacheStore interface {
+	// Get returns the value stored under key, and whether it was found and has not expired yet.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key, removing it after ttl. Values with a ttl of 0 or less are not stored.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// cacheEntry is a cached response body, together with its validators for conditional requests.
+type cacheEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+	fetchedAt    time.Time // fetchedAt is when the response was fetched or last revalidated
+	expiresAt    time.Time // expiresAt is when the entry becomes stale
+}
+
+// cacheEntryVersion is the first byte of encoded entries, so the format can be changed without misreading entries in shared stores.
+const cacheEntryVersion = 2
+
+// encode encodes the entry for a CacheStore.
+func (e *cacheEntry) encode() []byte {
+	b := make([]byte, 0, 1+2*8+2*binary.MaxVarintLen64+len(e.etag)+len(e.lastModified)+len(e.body))
+	b = append(b, cacheEntryVersion)
+	b = binary.BigEndian.AppendUint64(b, uint64(e.fetchedAt.UnixNano()))
+	b = binary.BigEndian.AppendUint64(b, uint64(e.expiresAt.UnixNano()))
+	b = binary.AppendUvarint(b, uint64(len(e.etag)))
+	b = append(b, e.etag...)
+	b = binary.AppendUvarint(b, uint64(len(e.lastModified)))
+	b = append(b, e.lastModified...)
+	return append(b, e.body...)
+}
+
+// decodeCacheEntry decodes an entry encoded by cacheEntry.encode, and reports whether b was valid.
+func decodeCacheEntry(b []byte) (*cacheEntry, bool) {
+	if len(b) < 17 || b[0] != cacheEntryVersion {
+		return nil, false
+	}
+	entry := &cacheEntry{
+		fetchedAt: time.Unix(0, int64(binary.BigEndian.Uint64(b[1:9]))),
+		expiresAt: time.Unix(0, int64(binary.BigEndian.Uint64(b[9:17]))),
+	}
+	b = b[17:]
+
+	for _, field := range []*string{&entry.etag, &entry.lastModified} {
+		n, size := binary.Uvarint(b)
+		if size <= 0 || uint64(len(b)-size) < n {
+			return nil, false
+		}
+		*field = string(b[size : size+int(n)])
+		b = b[size+int(n):]
+	}
+	entry.body = b
+	return entry, true
 }
 
-func newCache() *Cache {
+// isFresh reports whether the entry has not expired yet.
+func (e *cacheEntry) isFresh() bool {
+	return time.Now().Before(e.expiresAt)
+}
+
+// meta returns the ResponseMeta of the cached response.
+func (e *cacheEntry) meta(fromCache bool) ResponseMeta {
+	return ResponseMeta{FromCache: fromCache, FetchedAt: e.fetchedAt, ExpiresAt: e.expiresAt}
+}
+
+// hasValidators reports whether the entry can be revalidated using a conditional request.
+func (e *cacheEntry) hasValidators() bool {
+	return e.etag != "" || e.lastModified != ""
+}
+
+// setConditionalHeaders makes req a conditional request, which is answered with http.StatusNotModified if the entry is still up to date.
+func (e *cacheEntry) setConditionalHeaders(req *resty.Request) {
+	if e.etag != "" {
+		req.SetHeader("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.SetHeader("If-Modified-Since", e.lastModified)
+	}
+}
+
+// NewCache creates a new, enabled Cache, storing responses in a MemoryStore without limits. Pass it to New using WithCache to share it between multiple clients, and Close it once they are done.
+func NewCache() *Cache {
+	return newCacheWithStore(NewMemoryStore())
+}
+
+// Close closes the store of the cache if it implements io.Closer, like the MemoryStore created by NewCache.
+func (c *Cache) Close() error {
+	if closer, ok := c.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func newCacheWithStore(store CacheStore) *Cache {
 	return &Cache{
-		store:   cmap.New[*cachedValue](),
+		store:   store,
 		enabled: true,
 	}
 }
 
 // UseCache sets whether to use cache.
 //
-// Cache is capable of storing large amounts of data in memory, across different shards. Using it together with SetCacheTime may replace the need for a store like Redis, depending on your needs.
+// By default, responses are cached in memory. Use WithCacheStore to share cached responses between multiple processes, e.g. using a RedisStore.
 func (h *Client) UseCache(v bool) {
 	h.cache.mu.Lock()
 	defer h.cache.mu.Unlock()
@@ -45,44 +162,147 @@ func (h *Client) SetCacheTime(d time.Duration) {
 	h.cache.cacheTime = d
 }
 
-// Get gets a value from the cache, and a boolean indicating whether the value was found.
+// SetStaleWhileRevalidate sets for how long after expiring a cached response may still be returned, while it is refreshed in the background.
+// This keeps responses fast, at the cost of them being outdated for up to one request. Disable by passing 0 as argument, which is the default.
+func (h *Client) SetStaleWhileRevalidate(d time.Duration) {
+	h.cache.mu.Lock()
+	defer h.cache.mu.Unlock()
+	h.cache.staleWhileRevalidate = d
+}
+
+// isEnabled reports whether the cache is used.
+func (c *Cache) isEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.enabled
+}
+
+// staleFor returns for how long after expiring an entry may still be returned, while it is refreshed in the background.
+func (c *Cache) staleFor() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.staleWhileRevalidate
+}
+
+// fixedCacheTime returns the cache time set using SetCacheTime, or 0 if the Cache-Control header decides.
+func (c *Cache) fixedCacheTime() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cacheTime
+}
+
+// Get gets a value from the cache, and a boolean indicating whether the value was found. Expired values are not returned.
 func (c *Cache) Get(key string) ([]byte, bool) {
-	if !c.enabled {
+	if !c.isEnabled() {
 		return nil, false
 	}
 
-	value, ok := c.store.Get(key)
-	if !ok {
+	entry, ok := c.getEntry(key)
+	if !ok || !entry.isFresh() {
+		c.misses.Add(1)
 		return nil, false
 	}
-	return value.data, ok
+	c.hits.Add(1)
+	return entry.body, true
 }
 
 // Set sets a value in the cache, with a duration after it gets removed.
 func (c *Cache) Set(key string, data []byte, duration time.Duration) {
-	if !c.enabled {
+	if !c.isEnabled() {
 		return
 	}
-	if value, ok := c.store.Get(key); ok {
-		value.timer.Stop()
+	now := time.Now()
+	c.setEntry(key, &cacheEntry{body: data, fetchedAt: now, expiresAt: now.Add(duration)})
+}
+
+// Delete removes a value from the cache.
+func (c *Cache) Delete(key string) {
+	c.store.Delete(key)
+}
+
+// Stats returns statistics about the cache. Evictions, Entries and Bytes are only reported by stores that keep track of them, like MemoryStore.
+func (c *Cache) Stats() CacheStats {
+	var stats CacheStats
+	if reporter, ok := c.store.(statsReporter); ok {
+		stats = reporter.Stats()
 	}
-	c.store.Set(key, &cachedValue{
-		data: data,
-		timer: time.AfterFunc(duration, func() {
-			c.store.Remove(key)
-		}),
-	})
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	return stats
 }
 
-// CacheResponse caches the response body of a resty.Response, using the Cache-Control header to determine the cache time.
+// CacheStats returns statistics about the client's cache, see Cache.Stats.
+func (h *Client) CacheStats() CacheStats {
+	return h.cache.Stats()
+}
+
+// CacheResponse caches the response body of a resty.Response together with its validators, using the Cache-Control header to determine the cache time.
 func (c *Cache) CacheResponse(url string, res *resty.Response) {
-	if c.cacheTime > 0 {
-		c.Set(url, res.Body(), c.cacheTime)
+	if !c.isEnabled() {
 		return
 	}
-	seconds, err := strconv.Atoi(res.Header().Get("Cache-Control")[8:])
-	if err != nil {
+
+	if entry, ok := c.newEntry(res); ok {
+		c.setEntry(url, entry)
+	}
+}
+
+// newEntry creates an entry for a response, and reports whether the response may be cached. If not, expiresAt is zero.
+func (c *Cache) newEntry(res *resty.Response) (*cacheEntry, bool) {
+	entry := &cacheEntry{
+		body:         res.Body(),
+		etag:         res.Header().Get("ETag"),
+		lastModified: res.Header().Get("Last-Modified"),
+		fetchedAt:    time.Now(),
+	}
+	ttl, ok := c.freshFor(res)
+	if ok {
+		entry.expiresAt = entry.fetchedAt.Add(ttl)
+	}
+	return entry, ok
+}
+
+// refresh extends a stale entry after the API confirmed it is up to date, by responding to a conditional request with http.StatusNotModified.
+func (c *Cache) refresh(url string, entry *cacheEntry, res *resty.Response) {
+	ttl, ok := c.freshFor(res)
+	if !ok {
 		return
 	}
-	c.Set(url, res.Body(), time.Duration(seconds)*time.Second)
+	entry.fetchedAt = time.Now()
+	entry.expiresAt = entry.fetchedAt.Add(ttl)
+	if etag := res.Header().Get("ETag"); etag != "" {
+		entry.etag = etag
+	}
+	c.setEntry(url, entry)
+}
+
+// canServeStale reports whether a stale entry may be returned, while it is refreshed in the background.
+func (c *Cache) canServeStale(entry *cacheEntry) bool {
+	stale := c.staleFor()
+	return stale > 0 && time.Now().Before(entry.expiresAt.Add(stale))
+}
+
+// freshFor returns for how long a response is fresh, and false if it must not be cached. Without a fixed cache time, it is determined by the Cache-Control header.
+func (c *Cache) freshFor(res *resty.Response) (time.Duration, bool) {
+	if d := c.fixedCacheTime(); d > 0 {
+		return d, true
+	}
+	return parseCacheControl(res.Header().Values("Cache-Control")).freshFor()
+}
+
+func (c *Cache) getEntry(key string) (*cacheEntry, bool) {
+	data, ok := c.store.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return decodeCacheEntry(data)
+}
+
+// setEntry stores entry, keeping it beyond its expiry if it may be served stale or revalidated later.
+func (c *Cache) setEntry(key string, entry *cacheEntry) {
+	keep := c.staleFor()
+	if entry.hasValidators() {
+		keep = max(keep, revalidationWindow)
+	}
+	c.store.Set(key, entry.encode(), time.Until(entry.expiresAt)+keep)
 }
@@ -5,8 +5,6 @@ import (
 	"strconv"
 	"testing"
 	"time"
-
-	cmap "github.com/orcaman/concurrent-map/v2"
 )
 
 func randomBytes(size int) []byte {
@@ -26,73 +24,49 @@ var cacheBenchmarkArgs = []struct {
 	{"100KB", 1024 * 100},
 }
 
-func BenchmarkCacheReadPtr(b *testing.B) {
-	c := cmap.New[*cachedValue]()
-	timer := time.NewTimer(time.Minute)
-	for _, args := range cacheBenchmarkArgs {
-		for i := 0; i < b.N; i++ {
-			c.Set(strconv.Itoa(i), &cachedValue{
-				data:  randomBytes(args.size),
-				timer: timer,
-			})
-		}
-	}
-
+func BenchmarkMemoryStoreRead(b *testing.B) {
 	for _, args := range cacheBenchmarkArgs {
 		b.Run(args.name, func(b *testing.B) {
+			s := NewMemoryStore()
+			defer s.Close()
+			data := randomBytes(args.size)
 			for i := 0; i < b.N; i++ {
-				c.Get(strconv.Itoa(i))
+				s.Set(strconv.Itoa(i), data, time.Minute)
 			}
-		})
-	}
-}
 
-func BenchmarkCacheReadVal(b *testing.B) {
-	c := cmap.New[cachedValue]()
-	timer := time.NewTimer(time.Minute)
-	for _, args := range cacheBenchmarkArgs {
-		for i := 0; i < b.N; i++ {
-			c.Set(strconv.Itoa(i), cachedValue{
-				data:  randomBytes(args.size),
-				timer: timer,
-			})
-		}
-	}
-
-	for _, args := range cacheBenchmarkArgs {
-		b.Run(args.name, func(b *testing.B) {
+			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				c.Get(strconv.Itoa(i))
+				s.Get(strconv.Itoa(i))
 			}
 		})
 	}
 }
 
-func BenchmarkCacheWritePtr(b *testing.B) {
-	c := cmap.New[*cachedValue]()
-	timer := time.NewTimer(time.Minute)
+func BenchmarkMemoryStoreWrite(b *testing.B) {
 	for _, args := range cacheBenchmarkArgs {
 		b.Run(args.name, func(b *testing.B) {
+			s := NewMemoryStore()
+			defer s.Close()
+			data := randomBytes(args.size)
+
+			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				c.Set("key", &cachedValue{
-					data:  randomBytes(args.size),
-					timer: timer,
-				})
+				s.Set(strconv.Itoa(i), data, time.Minute)
 			}
 		})
 	}
 }
 
-func BenchmarkCacheWriteVal(b *testing.B) {
-	c := cmap.New[cachedValue]()
-	timer := time.NewTimer(time.Minute)
+func BenchmarkMemoryStoreWriteEvict(b *testing.B) {
 	for _, args := range cacheBenchmarkArgs {
 		b.Run(args.name, func(b *testing.B) {
+			s := NewMemoryStoreWithConfig(MemoryConfig{MaxEntries: 1000})
+			defer s.Close()
+			data := randomBytes(args.size)
+
+			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				c.Set(strconv.Itoa(i), cachedValue{
-					data:  randomBytes(args.size),
-					timer: timer,
-				})
+				s.Set(strconv.Itoa(i), data, time.Minute)
 			}
 		})
 	}
@@ -0,0 +1,58 @@
+package goclash
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the directives of Cache-Control headers that are relevant for caching responses on the client.
+type cacheControl struct {
+	maxAge    time.Duration
+	hasMaxAge bool
+	noStore   bool
+	noCache   bool
+}
+
+// parseCacheControl parses the values of Cache-Control headers, e.g. "public, max-age=60". Directive names are case-insensitive, and unknown directives are ignored.
+// If a directive appears more than once, the first one wins. Invalid max-age values are ignored.
+func parseCacheControl(values []string) cacheControl {
+	var cc cacheControl
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "max-age":
+				if cc.hasMaxAge {
+					continue
+				}
+				seconds, err := strconv.Atoi(strings.Trim(strings.TrimSpace(arg), `"`))
+				if err != nil || seconds < 0 {
+					continue
+				}
+				cc.maxAge = time.Duration(seconds) * time.Second
+				cc.hasMaxAge = true
+			case "no-store":
+				cc.noStore = true
+			case "no-cache":
+				cc.noCache = true
+			}
+		}
+	}
+	return cc
+}
+
+// freshFor returns for how long a response with these directives is fresh, and false if it must not be cached.
+// Responses with no-cache are cached, but are stale right away, so they are revalidated before being used.
+func (cc cacheControl) freshFor() (time.Duration, bool) {
+	switch {
+	case cc.noStore:
+		return 0, false
+	case cc.noCache:
+		return 0, true
+	case cc.hasMaxAge:
+		return cc.maxAge, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,106 @@
+package goclash
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore is a CacheStore that keeps every value in a file in a directory, so cached responses survive restarts and can be shared by processes on the same machine.
+//
+// Expired files are removed when they are read. Use Prune to remove all expired files at once.
+type FileStore struct {
+	dir string
+}
+
+// expiryHeaderSize is the size of the expiry time (Unix nanoseconds) each file starts with.
+const expiryHeaderSize = 8
+
+// NewFileStore creates a FileStore in dir, creating the directory if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Get implements CacheStore.
+func (s *FileStore) Get(key string) ([]byte, bool) {
+	path := s.path(key)
+	content, err := os.ReadFile(path)
+	if err != nil || len(content) < expiryHeaderSize {
+		return nil, false
+	}
+	if isExpired(content) {
+		os.Remove(path)
+		return nil, false
+	}
+	return content[expiryHeaderSize:], true
+}
+
+// Set implements CacheStore. The value is written to a temporary file first, so concurrent readers never see partially written values.
+func (s *FileStore) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	var header [expiryHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(time.Now().Add(ttl).UnixNano()))
+	_, err = tmp.Write(header[:])
+	if err == nil {
+		_, err = tmp.Write(value)
+	}
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete implements CacheStore.
+func (s *FileStore) Delete(key string) {
+	os.Remove(s.path(key))
+}
+
+// Prune removes all expired values from the directory.
+func (s *FileStore) Prune() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "tmp-") {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if len(content) < expiryHeaderSize || isExpired(content) {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// path returns the file a key is stored in. Keys are hashed, because URLs are no valid file names.
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+func isExpired(content []byte) bool {
+	expiry := int64(binary.BigEndian.Uint64(content[:expiryHeaderSize]))
+	return time.Now().UnixNano() > expiry
+}
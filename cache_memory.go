@@ -0,0 +1,177 @@
+package goclash
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const defaultCleanupInterval = time.Minute
+
+// MemoryStore is a simple but performant in-memory CacheStore.
+//
+// By default, it grows without limit. Use NewMemoryStoreWithConfig to limit the number of values or their total size,
+// in which case the least recently used values are evicted first. Expired values are removed by a single background goroutine, which is started by the first Set and stopped by Close.
+type MemoryStore struct {
+	cfg       MemoryConfig
+	values    map[string]*list.Element
+	lru       *list.List // lru holds *memoryValue, most recently used first
+	bytes     int64
+	evictions uint64
+	done      chan struct{}
+	startOnce sync.Once // startOnce starts the janitor on the first Set, so that unused stores don't leak a goroutine.
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+// MemoryConfig configures a MemoryStore. Zero values mean no limit.
+type MemoryConfig struct {
+	MaxEntries      int           // MaxEntries is the maximum number of stored values.
+	MaxBytes        int64         // MaxBytes is the maximum total size of the stored keys and values.
+	CleanupInterval time.Duration // CleanupInterval is how often expired values are removed, which defaults to one minute.
+}
+
+type memoryValue struct {
+	key    string
+	data   []byte
+	expiry time.Time
+}
+
+func (v *memoryValue) size() int64 {
+	return int64(len(v.key) + len(v.data))
+}
+
+// NewMemoryStore creates a new, empty MemoryStore without limits.
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithConfig(MemoryConfig{})
+}
+
+// NewMemoryStoreWithConfig creates a new, empty MemoryStore using cfg.
+func NewMemoryStoreWithConfig(cfg MemoryConfig) *MemoryStore {
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = defaultCleanupInterval
+	}
+	s := &MemoryStore{
+		cfg:    cfg,
+		values: make(map[string]*list.Element),
+		lru:    list.New(),
+		done:   make(chan struct{}),
+	}
+	return s
+}
+
+// Get implements CacheStore.
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.values[key]
+	if !ok {
+		return nil, false
+	}
+	value := elem.Value.(*memoryValue)
+	if time.Now().After(value.expiry) {
+		s.remove(elem)
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return value.data, true
+}
+
+// Set implements CacheStore. Values larger than MemoryConfig.MaxBytes are not stored.
+func (s *MemoryStore) Set(key string, data []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	value := &memoryValue{key: key, data: data, expiry: time.Now().Add(ttl)}
+	s.startOnce.Do(func() {
+		go s.janitor()
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.values[key]; ok {
+		s.remove(elem)
+	}
+	if s.cfg.MaxBytes > 0 && value.size() > s.cfg.MaxBytes {
+		return
+	}
+	s.values[key] = s.lru.PushFront(value)
+	s.bytes += value.size()
+
+	for s.overLimit() {
+		s.remove(s.lru.Back())
+		s.evictions++
+	}
+}
+
+// Delete implements CacheStore.
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.values[key]; ok {
+		s.remove(elem)
+	}
+}
+
+// Stats returns the number of evictions, values and bytes stored. Hits and misses are counted by Cache.
+func (s *MemoryStore) Stats() CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return CacheStats{
+		Evictions: s.evictions,
+		Entries:   s.lru.Len(),
+		Bytes:     s.bytes,
+	}
+}
+
+// Close stops the background goroutine removing expired values. The store remains usable, but expired values are only removed when they are read.
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+func (s *MemoryStore) overLimit() bool {
+	return (s.cfg.MaxEntries > 0 && s.lru.Len() > s.cfg.MaxEntries) ||
+		(s.cfg.MaxBytes > 0 && s.bytes > s.cfg.MaxBytes)
+}
+
+// remove removes elem from the store. The caller must hold s.mu.
+func (s *MemoryStore) remove(elem *list.Element) {
+	value := s.lru.Remove(elem).(*memoryValue)
+	delete(s.values, value.key)
+	s.bytes -= value.size()
+}
+
+// janitor periodically removes expired values until the store is closed.
+func (s *MemoryStore) janitor() {
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.removeExpired(now)
+		}
+	}
+}
+
+func (s *MemoryStore) removeExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for elem := s.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if now.After(elem.Value.(*memoryValue).expiry) {
+			s.remove(elem)
+		}
+		elem = next
+	}
+}
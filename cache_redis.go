@@ -0,0 +1,209 @@
+package goclash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisStore is a CacheStore backed by a Redis server (or any server speaking the Redis protocol), so multiple processes can share cached responses.
+// It only needs the GET, SET (with PX) and DEL commands.
+type RedisStore struct {
+	cfg   RedisConfig
+	conns chan *redisConn // conns is a pool of idle connections
+}
+
+// RedisConfig configures a RedisStore.
+type RedisConfig struct {
+	Addr      string        // Addr is the host:port of the server.
+	Password  string        // Password is used to AUTH, if not empty.
+	DB        int           // DB is the database to SELECT.
+	KeyPrefix string        // KeyPrefix is prepended to every key, which defaults to "goclash:".
+	PoolSize  int           // PoolSize is the maximum number of idle connections, which defaults to 10.
+	Timeout   time.Duration // Timeout is used for dialing and every command, which defaults to 5 seconds.
+}
+
+// NewRedisStore creates a RedisStore. Connections are established lazily, so the server does not need to be reachable yet.
+func NewRedisStore(cfg RedisConfig) *RedisStore {
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "goclash:"
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 10
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &RedisStore{
+		cfg:   cfg,
+		conns: make(chan *redisConn, cfg.PoolSize),
+	}
+}
+
+// Get implements CacheStore.
+func (s *RedisStore) Get(key string) ([]byte, bool) {
+	reply, err := s.do("GET", s.cfg.KeyPrefix+key)
+	if err != nil || reply == nil {
+		return nil, false
+	}
+	value, ok := reply.([]byte)
+	return value, ok
+}
+
+// Set implements CacheStore.
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	ms := max(ttl.Milliseconds(), 1)
+	s.do("SET", s.cfg.KeyPrefix+key, value, "PX", strconv.FormatInt(ms, 10))
+}
+
+// Delete implements CacheStore.
+func (s *RedisStore) Delete(key string) {
+	s.do("DEL", s.cfg.KeyPrefix+key)
+}
+
+// Close closes all idle connections.
+func (s *RedisStore) Close() error {
+	for {
+		select {
+		case conn := <-s.conns:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and returns its reply, which is nil, []byte, string or int64.
+func (s *RedisStore) do(args ...any) (any, error) {
+	conn, err := s.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(s.cfg.Timeout, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// the connection is in an unknown state
+		conn.Close()
+		return nil, err
+	}
+	s.putConn(conn)
+	return reply, err
+}
+
+func (s *RedisStore) getConn() (*redisConn, error) {
+	select {
+	case conn := <-s.conns:
+		return conn, nil
+	default:
+	}
+
+	c, err := net.DialTimeout("tcp", s.cfg.Addr, s.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c), w: bufio.NewWriter(c)}
+	if s.cfg.Password != "" {
+		if _, err = conn.do(s.cfg.Timeout, "AUTH", s.cfg.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if s.cfg.DB != 0 {
+		if _, err = conn.do(s.cfg.Timeout, "SELECT", strconv.Itoa(s.cfg.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (s *RedisStore) putConn(conn *redisConn) {
+	select {
+	case s.conns <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// redisConn is a connection speaking RESP, the Redis serialization protocol.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// redisError is an error reply sent by the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func (c *redisConn) do(timeout time.Duration, args ...any) (any, error) {
+	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			return nil, fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+		fmt.Fprintf(c.w, "$%d\r\n", len(b))
+		c.w.Write(b)
+		c.w.WriteString("\r\n")
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(c.r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply type %q", kind)
+	}
+}
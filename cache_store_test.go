@@ -0,0 +1,194 @@
+package goclash
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCacheStore checks the behavior every CacheStore must have.
+func testCacheStore(t *testing.T, store CacheStore) {
+	t.Helper()
+
+	if _, ok := store.Get("missing"); ok {
+		t.Fatal("Get returned a value for a missing key")
+	}
+
+	store.Set("key", []byte("value"), time.Minute)
+	if value, ok := store.Get("key"); !ok || string(value) != "value" {
+		t.Fatalf("Get = %q, %v; want value, true", value, ok)
+	}
+
+	store.Set("key", []byte("new"), time.Minute)
+	if value, ok := store.Get("key"); !ok || string(value) != "new" {
+		t.Fatalf("Get after overwrite = %q, %v; want new, true", value, ok)
+	}
+
+	store.Delete("key")
+	if _, ok := store.Get("key"); ok {
+		t.Fatal("Get returned a deleted value")
+	}
+
+	store.Set("short", []byte("value"), 20*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := store.Get("short"); ok {
+		t.Fatal("Get returned an expired value")
+	}
+
+	store.Set("zero", []byte("value"), 0)
+	if _, ok := store.Get("zero"); ok {
+		t.Fatal("value with zero ttl was stored")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testCacheStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCacheStore(t, store)
+}
+
+func TestRedisStore(t *testing.T) {
+	addr := startFakeRedis(t, "secret")
+	store := NewRedisStore(RedisConfig{Addr: addr, Password: "secret", DB: 1})
+	defer store.Close()
+	testCacheStore(t, store)
+}
+
+// startFakeRedis starts a minimal in-memory server speaking the Redis protocol, supporting the commands used by RedisStore.
+func startFakeRedis(t *testing.T, password string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	type entry struct {
+		value  string
+		expiry time.Time
+	}
+	var mu sync.Mutex
+	data := make(map[string]entry)
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		authed := password == ""
+		for {
+			args, err := readFakeRedisCommand(r)
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			switch cmd := strings.ToUpper(args[0]); {
+			case cmd == "AUTH":
+				authed = args[1] == password
+				fmt.Fprint(conn, "+OK\r\n")
+			case !authed:
+				fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			case cmd == "SELECT":
+				fmt.Fprint(conn, "+OK\r\n")
+			case cmd == "GET":
+				if e, ok := data[args[1]]; ok && time.Now().Before(e.expiry) {
+					fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(e.value), e.value)
+				} else {
+					fmt.Fprint(conn, "$-1\r\n")
+				}
+			case cmd == "SET":
+				ms, _ := strconv.Atoi(args[4])
+				data[args[1]] = entry{args[2], time.Now().Add(time.Duration(ms) * time.Millisecond)}
+				fmt.Fprint(conn, "+OK\r\n")
+			case cmd == "DEL":
+				delete(data, args[1])
+				fmt.Fprint(conn, ":1\r\n")
+			default:
+				fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+			}
+			mu.Unlock()
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func readFakeRedisCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	s := NewMemoryStoreWithConfig(MemoryConfig{MaxEntries: 2, MaxBytes: 100})
+	defer s.Close()
+
+	s.Set("a", []byte("1"), time.Minute)
+	s.Set("b", []byte("2"), time.Minute)
+	s.Get("a") // a is now used more recently than b
+	s.Set("c", []byte("3"), time.Minute)
+	if _, ok := s.Get("b"); ok {
+		t.Fatal("least recently used value was not evicted")
+	}
+	if _, ok := s.Get("a"); !ok {
+		t.Fatal("recently used value was evicted")
+	}
+
+	s.Set("big", make([]byte, 90), time.Minute)
+	stats := s.Stats()
+	if stats.Entries != 2 || stats.Bytes != 95 || stats.Evictions != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	s.Set("huge", make([]byte, 200), time.Minute)
+	if _, ok := s.Get("huge"); ok {
+		t.Fatal("value larger than MaxBytes was stored")
+	}
+}
+
+func TestCacheCloseStopsJanitor(t *testing.T) {
+	cache := NewCache()
+	store := cache.store.(*MemoryStore)
+	store.Set("a", []byte("1"), time.Minute)
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-store.done:
+	default:
+		t.Fatal("Close did not close the MemoryStore of the cache")
+	}
+}
@@ -0,0 +1,214 @@
+package goclash
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheRevalidatesWithETag(t *testing.T) {
+	var requests, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		player, err := client.GetPlayer("#2PP")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.Name != "test" {
+			t.Fatalf("request %d returned player %q, want test", i, player.Name)
+		}
+	}
+	if requests.Load() != 3 || notModified.Load() != 2 {
+		t.Fatalf("got %d requests, %d not modified; want 3 requests, 2 not modified", requests.Load(), notModified.Load())
+	}
+	if stats := client.CacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("got %d hits and %d misses, want 2 hits and 1 miss", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprintf(w, `{"tag":"#2PP","name":"v%d"}`, n)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithStaleWhileRevalidate(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if player, err := client.GetPlayer("#2PP"); err != nil || player.Name != "v1" {
+		t.Fatalf("first request returned %v, %v", player, err)
+	}
+	// the stale response is returned right away, and refreshed in the background
+	if player, err := client.GetPlayer("#2PP"); err != nil || player.Name != "v1" {
+		t.Fatalf("second request returned %v, %v", player, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		player, err := client.GetPlayer("#2PP")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.Name != "v1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale response was not refreshed in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		header []string
+		ttl    time.Duration
+		ok     bool
+	}{
+		{nil, 0, false},
+		{[]string{""}, 0, false},
+		{[]string{"max-age"}, 0, false},
+		{[]string{"max-age=abc"}, 0, false},
+		{[]string{"max-age=60"}, time.Minute, true},
+		{[]string{"public, Max-Age=120"}, 2 * time.Minute, true},
+		{[]string{`private, max-age="30"`}, 30 * time.Second, true},
+		{[]string{"public", "max-age=10"}, 10 * time.Second, true},
+		{[]string{"max-age=10, max-age=20"}, 10 * time.Second, true},
+		{[]string{"no-cache, max-age=60"}, 0, true},
+		{[]string{"max-age=60, no-store"}, 0, false},
+	}
+	for _, tt := range tests {
+		ttl, ok := parseCacheControl(tt.header).freshFor()
+		if ttl != tt.ttl || ok != tt.ok {
+			t.Errorf("parseCacheControl(%q) = %v, %v; want %v, %v", tt.header, ttl, ok, tt.ttl, tt.ok)
+		}
+	}
+}
+
+func TestResponseMeta(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	first, err := client.GetPlayer("#2PP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.FromCache {
+		t.Fatal("first response is from cache")
+	}
+	if ttl := first.ExpiresAt.Sub(first.FetchedAt); ttl != time.Minute {
+		t.Fatalf("first response expires after %v, want 1m", ttl)
+	}
+
+	second, err := client.GetPlayer("#2PP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.FromCache || !second.FetchedAt.Equal(first.FetchedAt) || !second.ExpiresAt.Equal(first.ExpiresAt) {
+		t.Fatalf("second response has meta %+v, want cached %+v", second.ResponseMeta, first.ResponseMeta)
+	}
+
+	location, err := client.GetLocation(32000094)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.Name != "test" || location.FetchedAt.IsZero() {
+		t.Fatalf("GetLocation returned %+v, want its name and meta", location)
+	}
+}
+
+func TestCacheStaleWhileRevalidateNotModified(t *testing.T) {
+	var requests, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			// keep the revalidation in flight while the other stale hits arrive
+			time.Sleep(20 * time.Millisecond)
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithStaleWhileRevalidate(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err = client.GetPlayer("#2PP"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			player, err := client.GetPlayer("#2PP")
+			if err != nil || player.Name != "test" || !player.FromCache {
+				t.Errorf("stale request returned %+v, %v", player, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		player, err := client.GetPlayer("#2PP")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.ExpiresAt.After(time.Now()) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale response was not revalidated in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if requests.Load() != 2 || notModified.Load() != 1 {
+		t.Fatalf("got %d requests, %d not modified; want 2 requests, 1 not modified", requests.Load(), notModified.Load())
+	}
+}
@@ -1,14 +1,21 @@
 package goclash
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
-	"sync"
-
-	"github.com/bytedance/sonic"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type Clan struct {
+	ResponseMeta `json:"-"`
+
 	WarLeague                   WarLeague     `json:"warLeague"`
 	CapitalLeague               CapitalLeague `json:"capitalLeague"`
 	MemberList                  []ClanMember  `json:"memberList"`
@@ -17,9 +24,9 @@ type Clan struct {
 	BuilderBasePoints           int           `json:"clanBuilderBasePoints"`
 	RequiredBuilderBaseTrophies int           `json:"requiredBuilderBaseTrophies"`
 	RequiredTownHallLevel       int           `json:"requiredTownhallLevel"`
-	IsFamilyFriendly            bool          `json:"IsFamilyFriendly"`
+	IsFamilyFriendly            bool          `json:"isFamilyFriendly"`
 	IsWarLogPublic              bool          `json:"isWarLogPublic"`
-	WarFrequency                string        `json:"warFrequency"`
+	WarFrequency                WarFrequency  `json:"warFrequency"`
 	Level                       int           `json:"clanLevel"`
 	WarWinStreak                int           `json:"warWinStreak"`
 	WarWins                     int           `json:"warWins"`
@@ -31,7 +38,7 @@ type Clan struct {
 	Labels                      []Label       `json:"labels"`
 	Name                        string        `json:"name"`
 	Location                    Location      `json:"location"`
-	Type                        string        `json:"type"`
+	Type                        ClanType      `json:"type"`
 	MemberCount                 int           `json:"members"`
 	Description                 string        `json:"description"`
 	ClanCapital                 ClanCapital   `json:"clanCapital"`
@@ -49,8 +56,20 @@ func (c Clans) Tags() []string {
 }
 
 type ClanMember struct {
-	Tag  string `json:"tag"`
-	Name string `json:"name"`
+	League              League            `json:"league"`
+	BuilderBaseLeague   BuilderBaseLeague `json:"builderBaseLeague"`
+	PlayerHouse         PlayerHouse       `json:"playerHouse"`
+	Tag                 string            `json:"tag"`
+	Name                string            `json:"name"`
+	Role                ClanRole          `json:"role"`
+	TownHallLevel       int               `json:"townHallLevel"`
+	ExpLevel            int               `json:"expLevel"`
+	Trophies            int               `json:"trophies"`
+	BuilderBaseTrophies int               `json:"builderBaseTrophies"`
+	ClanRank            int               `json:"clanRank"`
+	PreviousClanRank    int               `json:"previousClanRank"`
+	Donations           int               `json:"donations"`
+	DonationsReceived   int               `json:"donationsReceived"`
 }
 
 type ClanRole string
@@ -67,6 +86,27 @@ func (r ClanRole) String() string {
 	return string(r)
 }
 
+// IsValid reports whether r is a known ClanRole.
+func (r ClanRole) IsValid() bool {
+	return r.Format() != ""
+}
+
+// Rank returns the rank of the role within a clan, from 0 for ClanRoleNotMember to 4 for ClanRoleLeader. Unknown roles have rank 0.
+func (r ClanRole) Rank() int {
+	switch r {
+	case ClanRoleMember:
+		return 1
+	case ClanRoleAdmin:
+		return 2
+	case ClanRoleCoLeader:
+		return 3
+	case ClanRoleLeader:
+		return 4
+	default:
+		return 0
+	}
+}
+
 func (r ClanRole) Format() string {
 	switch r {
 	case ClanRoleNotMember:
@@ -84,16 +124,6 @@ func (r ClanRole) Format() string {
 	}
 }
 
-const (
-	WarFrequencyUnknown       = "unknown"
-	WarFrequencyAlways        = "always"
-	WarFrequencyMTOncePerWeek = "moreThanOncePerWeek"
-	WarFrequencyOncePerWeek   = "oncePerWeek"
-	WarFrequencyLTOncePerWeek = "lessThanOncePerWeek"
-	WarFrequencyNever         = "never"
-	WarFrequencyAny           = "any"
-)
-
 type Language struct {
 	Name         string `json:"name"`
 	ID           int    `json:"id"`
@@ -112,36 +142,69 @@ type ClanDistrict struct {
 }
 
 type ClanWar struct {
-	Clan                 WarClan      `json:"clan"`
-	Opponent             WarClan      `json:"opponent"`
-	TeamSize             int          `json:"teamSize"`
-	StartTime            string       `json:"startTime"`
-	State                ClanWarState `json:"state"`
-	EndTime              string       `json:"endTime"`
-	PreparationStartTime string       `json:"preparationStartTime"`
+	ResponseMeta `json:"-"`
+
+	Clan                 WarClan        `json:"clan"`
+	Opponent             WarClan        `json:"opponent"`
+	TeamSize             int            `json:"teamSize"`
+	AttacksPerMember     int            `json:"attacksPerMember"`
+	BattleModifier       BattleModifier `json:"battleModifier"`
+	StartTime            Time           `json:"startTime"`
+	State                ClanWarState   `json:"state"`
+	EndTime              Time           `json:"endTime"`
+	PreparationStartTime Time           `json:"preparationStartTime"`
+}
+
+// PreparationTimeRemaining returns the time left until the preparation day ends and the battle day starts, or 0 if the war is not in preparation.
+func (w *ClanWar) PreparationTimeRemaining() time.Duration {
+	if w.State != ClanWarStatePreparation {
+		return 0
+	}
+	return w.StartTime.Remaining()
 }
 
-type ClanWarState = string
+// TimeRemaining returns the time left until the war ends, or 0 if it ended or there is no war.
+func (w *ClanWar) TimeRemaining() time.Duration {
+	return w.EndTime.Remaining()
+}
 
-const (
-	ClanWarStateClanNotFound  ClanWarState = "clanNotFound"
-	ClanWarStateAccessDenied  ClanWarState = "accessDenied"
-	ClanWarStateNotInWar      ClanWarState = "notInWar"
-	ClanWarStateInMatchmaking ClanWarState = "inMatchmaking"
-	ClanWarStateEnterWar      ClanWarState = "enterWar"
-	ClanWarStateMatched       ClanWarState = "matched"
-	ClanWarStatePreparation   ClanWarState = "preparation"
-	ClanWarStateWar           ClanWarState = "war"
-	ClanWarStateInWar         ClanWarState = "inWar"
-	ClanWarStateEnded         ClanWarState = "ended"
-)
+// Orient returns the war from the perspective of the clan with the given tag, swapping Clan and Opponent if needed, and false if the clan is not part of the war.
+func (w *ClanWar) Orient(clanTag Tag) (*ClanWar, bool) {
+	switch normalizeTag(clanTag).String() {
+	case w.Clan.Tag:
+		return w, true
+	case w.Opponent.Tag:
+		oriented := *w
+		oriented.Clan, oriented.Opponent = w.Opponent, w.Clan
+		return &oriented, true
+	default:
+		return nil, false
+	}
+}
+
+// ClanWarLeagueWar is a single war within a clan war league.
+type ClanWarLeagueWar struct {
+	ClanWar
+	WarStartTime Time `json:"warStartTime"`
+}
+
+// Orient returns the war from the perspective of the clan with the given tag, like ClanWar.Orient.
+func (w *ClanWarLeagueWar) Orient(clanTag Tag) (*ClanWarLeagueWar, bool) {
+	war, ok := w.ClanWar.Orient(clanTag)
+	if !ok {
+		return nil, false
+	}
+	return &ClanWarLeagueWar{ClanWar: *war, WarStartTime: w.WarStartTime}, true
+}
 
 type ClanWarLeagueGroup struct {
+	ResponseMeta `json:"-"`
+
 	Tag    string                  `json:"tag"`
 	State  ClanWarLeagueGroupState `json:"state"`
 	Season string                  `json:"season"`
-	Clans  []ClanWarLeagueClan
-	Rounds []ClanWarLeagueRound
+	Clans  []ClanWarLeagueClan     `json:"clans"`
+	Rounds []ClanWarLeagueRound    `json:"rounds"`
 }
 
 type ClanWarLeagueClan struct {
@@ -163,12 +226,13 @@ type ClanWarLeagueRound struct {
 }
 
 type ClanWarLogEntry struct {
-	Clan             WarClan       `json:"clan"`
-	Opponent         WarClan       `json:"opponent"`
-	TeamSize         int           `json:"teamSize"`
-	AttacksPerMember int           `json:"attacksPerMember"`
-	EndTime          string        `json:"endTime"`
-	Result           ClanWarResult `json:"result"`
+	Clan             WarClan        `json:"clan"`
+	Opponent         WarClan        `json:"opponent"`
+	TeamSize         int            `json:"teamSize"`
+	AttacksPerMember int            `json:"attacksPerMember"`
+	BattleModifier   BattleModifier `json:"battleModifier"`
+	EndTime          Time           `json:"endTime"`
+	Result           ClanWarResult  `json:"result"` // Result is empty for wars of a clan war league.
 }
 
 type WarClan struct {
@@ -187,7 +251,7 @@ type ClanWarMember struct {
 	Tag                string          `json:"tag"`
 	Name               string          `json:"name"`
 	MapPosition        int             `json:"mapPosition"`
-	TownHallLevel      int             `json:"townHallLevel"`
+	TownHallLevel      int             `json:"townhallLevel"`
 	OpponentAttacks    int             `json:"opponentAttacks"`
 	BestOpponentAttack *ClanWarAttack  `json:"bestOpponentAttack,omitempty"`
 	Attacks            []ClanWarAttack `json:"attacks,omitempty"`
@@ -202,71 +266,153 @@ type ClanWarAttack struct {
 	Duration              int    `json:"duration"`
 }
 
+// SearchClanParams are the filters of SearchClans. At least one filter must be set, and filters left at their zero value are not sent. See Validate.
+//
+// Filters can also be composed using the With methods, e.g. SearchClanParams{}.WithLocation(location).WithLabels(labels...).
 type SearchClanParams struct {
 	*PagingParams
-	Name          string   `json:"name,omitempty"`
-	WarFrequency  string   `json:"warFrequency,omitempty"`
-	LocationID    string   `json:"locationId,omitempty"`
-	MinMembers    string   `json:"minMembers,omitempty"`
-	MaxMembers    string   `json:"maxMembers,omitempty"`
-	MinClanPoints string   `json:"minClanPoints,omitempty"`
-	MinClanLevel  string   `json:"minClanLevel,omitempty"`
-	LabelIDs      []string `json:"labelIds,omitempty"`
+	Name          string       `json:"name,omitempty"` // Name must be at least 3 characters long.
+	WarFrequency  WarFrequency `json:"warFrequency,omitempty"`
+	LocationID    int          `json:"locationId,omitempty"`
+	MinMembers    int          `json:"minMembers,omitempty"` // MinMembers must be between 2 and 50.
+	MaxMembers    int          `json:"maxMembers,omitempty"` // MaxMembers must be between 2 and 50, and not less than MinMembers.
+	MinClanPoints int          `json:"minClanPoints,omitempty"`
+	MinClanLevel  int          `json:"minClanLevel,omitempty"` // MinClanLevel must be at least 2.
+	LabelIDs      []int        `json:"labelIds,omitempty"`
 }
 
-func (p SearchClanParams) build() url.Values {
-	values := url.Values{}
-	if p.Name != "" {
-		values.Set("name", p.Name)
+const (
+	minSearchNameLength = 3
+	minSearchMembers    = 2
+	maxSearchMembers    = 50
+	minSearchClanLevel  = 2
+)
+
+// Validate checks the params against the rules of the API, so invalid searches fail before a request is sent. The returned error joins all violated rules.
+func (p SearchClanParams) Validate() error {
+	var errs []error
+	if p.Name == "" && p.WarFrequency == "" && p.LocationID == 0 && p.MinMembers == 0 && p.MaxMembers == 0 &&
+		p.MinClanPoints == 0 && p.MinClanLevel == 0 && len(p.LabelIDs) == 0 {
+		errs = append(errs, errors.New("search clan params: at least one filter must be set"))
 	}
-	if p.WarFrequency != "" {
-		values.Set("warFrequency", p.WarFrequency)
+	if p.Name != "" && utf8.RuneCountInString(p.Name) < minSearchNameLength {
+		errs = append(errs, fmt.Errorf("search clan params: name must be at least %d characters long, got %q", minSearchNameLength, p.Name))
 	}
-	if p.LocationID != "" {
-		values.Set("locationId", p.LocationID)
+	if p.WarFrequency != "" && !p.WarFrequency.IsValid() {
+		errs = append(errs, fmt.Errorf("search clan params: invalid war frequency %q", p.WarFrequency))
 	}
-	if p.MinMembers != "" {
-		values.Set("minMembers", p.MinMembers)
+	if p.LocationID < 0 {
+		errs = append(errs, fmt.Errorf("search clan params: invalid location ID %d", p.LocationID))
 	}
-	if p.MaxMembers != "" {
-		values.Set("maxMembers", p.MaxMembers)
+	if p.MinMembers != 0 && (p.MinMembers < minSearchMembers || p.MinMembers > maxSearchMembers) {
+		errs = append(errs, fmt.Errorf("search clan params: min members must be between %d and %d, got %d", minSearchMembers, maxSearchMembers, p.MinMembers))
 	}
-	if p.MinClanPoints != "" {
-		values.Set("minClanPoints", p.MinClanPoints)
+	if p.MaxMembers != 0 && (p.MaxMembers < minSearchMembers || p.MaxMembers > maxSearchMembers) {
+		errs = append(errs, fmt.Errorf("search clan params: max members must be between %d and %d, got %d", minSearchMembers, maxSearchMembers, p.MaxMembers))
 	}
-	if p.MinClanLevel != "" {
-		values.Set("minClanLevel", p.MinClanLevel)
+	if p.MinMembers != 0 && p.MaxMembers != 0 && p.MinMembers > p.MaxMembers {
+		errs = append(errs, fmt.Errorf("search clan params: min members must not exceed max members, got %d and %d", p.MinMembers, p.MaxMembers))
 	}
-	if len(p.LabelIDs) > 0 {
-		values["labelIds"] = p.LabelIDs
+	if p.MinClanPoints < 0 {
+		errs = append(errs, fmt.Errorf("search clan params: min clan points must not be negative, got %d", p.MinClanPoints))
 	}
-	return values
+	if p.MinClanLevel != 0 && p.MinClanLevel < minSearchClanLevel {
+		errs = append(errs, fmt.Errorf("search clan params: min clan level must be at least %d, got %d", minSearchClanLevel, p.MinClanLevel))
+	}
+	return errors.Join(errs...)
+}
+
+// WithName returns a copy of p, filtering by name.
+func (p SearchClanParams) WithName(name string) SearchClanParams {
+	p.Name = name
+	return p
 }
 
-type ClanWarLeagueGroupState = string
+// WithWarFrequency returns a copy of p, filtering by war frequency.
+func (p SearchClanParams) WithWarFrequency(frequency WarFrequency) SearchClanParams {
+	p.WarFrequency = frequency
+	return p
+}
 
-const (
-	ClanWarLeagueGroupStateNotFound ClanWarLeagueGroupState = "groupNotFound"
-	ClanWarLeagueGroupStateNotInWar ClanWarLeagueGroupState = "notInWar"
-	ClanWarLeagueGroupStatePrep     ClanWarLeagueGroupState = "preparation"
-	ClanWarLeagueGroupStateWar      ClanWarLeagueGroupState = "war"
-	ClanWarLeagueGroupStateEnded    ClanWarLeagueGroupState = "ended"
-)
+// WithLocation returns a copy of p, filtering by location, e.g. one returned by GetLocations.
+func (p SearchClanParams) WithLocation(location Location) SearchClanParams {
+	p.LocationID = location.ID
+	return p
+}
 
-type ClanWarResult = string
+// WithMembers returns a copy of p, filtering by the number of members. Pass 0 to leave either bound unset.
+func (p SearchClanParams) WithMembers(minMembers, maxMembers int) SearchClanParams {
+	p.MinMembers, p.MaxMembers = minMembers, maxMembers
+	return p
+}
 
-const (
-	ClanWarResultWin  ClanWarResult = "win"
-	ClanWarResultLose ClanWarResult = "lose"
-	ClanWarResultTie  ClanWarResult = "tie"
-)
+// WithMinClanPoints returns a copy of p, filtering by minimum clan points.
+func (p SearchClanParams) WithMinClanPoints(points int) SearchClanParams {
+	p.MinClanPoints = points
+	return p
+}
+
+// WithMinClanLevel returns a copy of p, filtering by minimum clan level.
+func (p SearchClanParams) WithMinClanLevel(level int) SearchClanParams {
+	p.MinClanLevel = level
+	return p
+}
+
+// WithLabels returns a copy of p, filtering by labels, e.g. ones returned by GetClanLabels. Clans must have all of the labels.
+func (p SearchClanParams) WithLabels(labels ...Label) SearchClanParams {
+	p.LabelIDs = make([]int, len(labels))
+	for i, label := range labels {
+		p.LabelIDs[i] = label.ID
+	}
+	return p
+}
+
+// WithLimit returns a copy of p, limiting the number of clans per page.
+func (p SearchClanParams) WithLimit(limit int) SearchClanParams {
+	paging := PagingParams{}
+	if p.PagingParams != nil {
+		paging = *p.PagingParams
+	}
+	paging.Limit = limit
+	p.PagingParams = &paging
+	return p
+}
+
+func (p SearchClanParams) build() url.Values {
+	values := url.Values{}
+	if p.Name != "" {
+		values.Set("name", p.Name)
+	}
+	if p.WarFrequency != "" {
+		values.Set("warFrequency", p.WarFrequency.String())
+	}
+	for key, value := range map[string]int{
+		"locationId":    p.LocationID,
+		"minMembers":    p.MinMembers,
+		"maxMembers":    p.MaxMembers,
+		"minClanPoints": p.MinClanPoints,
+		"minClanLevel":  p.MinClanLevel,
+	} {
+		if value != 0 {
+			values.Set(key, strconv.Itoa(value))
+		}
+	}
+	if len(p.LabelIDs) > 0 {
+		ids := make([]string, len(p.LabelIDs))
+		for i, id := range p.LabelIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		values.Set("labelIds", strings.Join(ids, ","))
+	}
+	return values
+}
 
 type ClanCapitalRaidSeason struct {
 	AttackLog               []ClanCapitalRaidSeasonAttackLogEntry  `json:"attackLog"`
 	DefenseLog              []ClanCapitalRaidSeasonDefenseLogEntry `json:"defenseLog"`
 	State                   string                                 `json:"state"`
-	StartTime               string                                 `json:"startTime"`
-	EndTime                 string                                 `json:"endTime"`
+	StartTime               Time                                   `json:"startTime"`
+	EndTime                 Time                                   `json:"endTime"`
 	CapitalTotalLoot        int                                    `json:"capitalTotalLoot"`
 	RaidsCompleted          int                                    `json:"raidsCompleted"`
 	TotalAttacks            int                                    `json:"totalAttacks"`
@@ -276,6 +422,11 @@ type ClanCapitalRaidSeason struct {
 	Members                 []ClanCapitalRaidSeasonMember          `json:"members"`
 }
 
+// TimeRemaining returns the time left until the raid weekend ends, or 0 if it ended.
+func (s *ClanCapitalRaidSeason) TimeRemaining() time.Duration {
+	return s.EndTime.Remaining()
+}
+
 type ClanCapitalRaidSeasonAttackLogEntry struct {
 	Defender           ClanCapitalRaidSeasonClanInfo   `json:"defender"`
 	AttackCount        int                             `json:"attackCount"`
@@ -333,143 +484,199 @@ type ClanCapitalRaidSeasonAttacker struct {
 // GetCurrentClanWarLeagueGroup returns the current war league group for a clan.
 //
 // GET /clans/{clanTag}/currentwar/leaguegroup
-func (h *Client) GetCurrentClanWarLeagueGroup(tag string) (*ClanWarLeagueGroup, error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag, "currentwar/leaguegroup"), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetCurrentClanWarLeagueGroup(tag Tag) (*ClanWarLeagueGroup, error) {
+	return h.GetCurrentClanWarLeagueGroupCtx(context.Background(), tag)
+}
+
+// GetCurrentClanWarLeagueGroupCtx is like GetCurrentClanWarLeagueGroup, but uses ctx for the request.
+func (h *Client) GetCurrentClanWarLeagueGroupCtx(ctx context.Context, tag Tag) (*ClanWarLeagueGroup, error) {
+	escaped, err := escapeTag(tag)
 	if err != nil {
 		return nil, err
 	}
-
-	var group *ClanWarLeagueGroup
-	err = sonic.Unmarshal(data, &group)
-	return group, err
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped, "currentwar/leaguegroup"), h.newDefaultRequest(), true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[ClanWarLeagueGroup](data, meta)
 }
 
 // GetClanWarLeagueWar returns information about a single war within a clan war league.
 //
 // GET /clanwarleagues/wars/{warTag}
-func (h *Client) GetClanWarLeagueWar(warTag string) (*ClanWarLeagueGroup, error) {
-	data, err := h.do(http.MethodGet, ClanWarLeaguesEndpoint.Build("wars", warTag), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetClanWarLeagueWar(warTag Tag) (*ClanWarLeagueWar, error) {
+	return h.GetClanWarLeagueWarCtx(context.Background(), warTag)
+}
+
+// GetClanWarLeagueWarCtx is like GetClanWarLeagueWar, but uses ctx for the request.
+func (h *Client) GetClanWarLeagueWarCtx(ctx context.Context, warTag Tag) (*ClanWarLeagueWar, error) {
+	escaped, err := escapeTag(warTag)
 	if err != nil {
 		return nil, err
 	}
-
-	var war *ClanWarLeagueGroup
-	err = sonic.Unmarshal(data, &war)
-	return war, err
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClanWarLeaguesEndpoint, "wars", escaped), h.newDefaultRequest(), true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[ClanWarLeagueWar](data, meta)
 }
 
 // GetClanWarLog returns a clan's war log.
 //
 // GET /clans/{clanTag}/warlog
-func (h *Client) GetClanWarLog(tag string, params *PagingParams) (*PaginatedResponse[ClanWarLogEntry], error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag, "warlog"), req, true)
+func (h *Client) GetClanWarLog(tag Tag, params *PagingParams) (*PaginatedResponse[ClanWarLogEntry], error) {
+	return h.GetClanWarLogCtx(context.Background(), tag, params)
+}
+
+// GetClanWarLogCtx is like GetClanWarLog, but uses ctx for the request.
+func (h *Client) GetClanWarLogCtx(ctx context.Context, tag Tag, params *PagingParams) (*PaginatedResponse[ClanWarLogEntry], error) {
+	escaped, err := escapeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped, "warlog"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[ClanWarLogEntry]](data, meta)
+}
 
-	var log *PaginatedResponse[ClanWarLogEntry]
-	err = sonic.Unmarshal(data, &log)
-	return log, err
+// IterClanWarLog is like GetClanWarLog, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanWarLog(ctx context.Context, tag Tag, params *PagingParams) iter.Seq2[ClanWarLogEntry, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanWarLogEntry], error) {
+		return h.GetClanWarLogCtx(ctx, tag, params)
+	}, params)
 }
 
-// SearchClans returns a list of clans that match the given params.
+// SearchClans returns a list of clans that match the given params. Invalid params are rejected without sending a request, see SearchClanParams.Validate.
 //
 // GET /clans
 func (h *Client) SearchClans(params SearchClanParams) (*PaginatedResponse[Clan], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params.PagingParams).
+	return h.SearchClansCtx(context.Background(), params)
+}
+
+// SearchClansCtx is like SearchClans, but uses ctx for the request.
+func (h *Client) SearchClansCtx(ctx context.Context, params SearchClanParams) (*PaginatedResponse[Clan], error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	req := h.withPaging(h.newDefaultRequest(), params.PagingParams).
 		SetQueryParamsFromValues(params.build())
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(), req, true)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[Clan]](data, meta)
+}
 
-	var clans *PaginatedResponse[Clan]
-	err = sonic.Unmarshal(data, &clans)
-	return clans, err
+// IterSearchClans is like SearchClans, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterSearchClans(ctx context.Context, params SearchClanParams) iter.Seq2[Clan, error] {
+	return Paginate(ctx, func(ctx context.Context, paging *PagingParams) (*PaginatedResponse[Clan], error) {
+		params.PagingParams = paging
+		return h.SearchClansCtx(ctx, params)
+	}, params.PagingParams)
 }
 
 // GetCurrentClanWar returns information about a clan's current clan war.
 //
 // GET /clans/{clanTag}/currentwar
-func (h *Client) GetCurrentClanWar(tag string) (*ClanWar, error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag, "currentwar"), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetCurrentClanWar(tag Tag) (*ClanWar, error) {
+	return h.GetCurrentClanWarCtx(context.Background(), tag)
+}
+
+// GetCurrentClanWarCtx is like GetCurrentClanWar, but uses ctx for the request.
+func (h *Client) GetCurrentClanWarCtx(ctx context.Context, tag Tag) (*ClanWar, error) {
+	escaped, err := escapeTag(tag)
 	if err != nil {
 		return nil, err
 	}
-
-	var war *ClanWar
-	err = sonic.Unmarshal(data, &war)
-	return war, err
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped, "currentwar"), h.newDefaultRequest(), true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[ClanWar](data, meta)
 }
 
 // GetClan returns a clan by its tag.
 //
 // GET /clans/{clanTag}
-func (h *Client) GetClan(tag string) (*Clan, error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withAuth(h.newDefaultRequest())
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag), req, true)
+func (h *Client) GetClan(tag Tag) (*Clan, error) {
+	return h.GetClanCtx(context.Background(), tag)
+}
+
+// GetClanCtx is like GetClan, but uses ctx for the request.
+func (h *Client) GetClanCtx(ctx context.Context, tag Tag) (*Clan, error) {
+	escaped, err := escapeTag(tag)
 	if err != nil {
 		return nil, err
 	}
-
-	var clan *Clan
-	err = sonic.Unmarshal(data, &clan)
-	return clan, err
+	req := h.newDefaultRequest()
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped), req, true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[Clan](data, meta)
 }
 
 // GetClans makes use of concurrency to get multiple clans simultaneously. The original order of the tags is preserved.
-func (h *Client) GetClans(tags ...string) (Clans, error) {
-	var wg sync.WaitGroup
-	clans := make(Clans, len(tags))
-	errChan := make(chan error, len(tags))
-
-	for i, tag := range tags {
-		wg.Add(1)
-		go func(i int, tag string) {
-			defer wg.Done()
-			clan, err := h.GetClan(tag)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			clans[i] = clan
-		}(i, tag)
-	}
-	wg.Wait()
-
-	if len(errChan) > 0 {
-		return nil, <-errChan
-	}
-	return clans, nil
-}
-
-func (h *Client) GetClanMembers(tag string, params *PagingParams) (*PaginatedResponse[ClanMember], error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag, "members"), req, true)
+// If any of the clans failed to be fetched, the error joins a *TagError for each of them, and they are nil in the returned slice. Use FetchClans for more control over failed tags.
+func (h *Client) GetClans(tags ...Tag) (Clans, error) {
+	return h.GetClansCtx(context.Background(), tags...)
+}
+
+// GetClansCtx is like GetClans, but uses ctx for the requests.
+func (h *Client) GetClansCtx(ctx context.Context, tags ...Tag) (Clans, error) {
+	res := h.FetchClans(ctx, tags...)
+	return res.Values(), res.Err()
+}
+
+func (h *Client) GetClanMembers(tag Tag, params *PagingParams) (*PaginatedResponse[ClanMember], error) {
+	return h.GetClanMembersCtx(context.Background(), tag, params)
+}
+
+// GetClanMembersCtx is like GetClanMembers, but uses ctx for the request.
+func (h *Client) GetClanMembersCtx(ctx context.Context, tag Tag, params *PagingParams) (*PaginatedResponse[ClanMember], error) {
+	escaped, err := escapeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped, "members"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[ClanMember]](data, meta)
+}
+
+// IterClanMembers is like GetClanMembers, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanMembers(ctx context.Context, tag Tag, params *PagingParams) iter.Seq2[ClanMember, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanMember], error) {
+		return h.GetClanMembersCtx(ctx, tag, params)
+	}, params)
+}
 
-	var members *PaginatedResponse[ClanMember]
-	err = sonic.Unmarshal(data, &members)
-	return members, err
+func (h *Client) GetClanCapitalRaidSeasons(tag Tag, params *PagingParams) (*PaginatedResponse[ClanCapitalRaidSeason], error) {
+	return h.GetClanCapitalRaidSeasonsCtx(context.Background(), tag, params)
 }
 
-func (h *Client) GetClanCapitalRaidSeasons(tag string, params *PagingParams) (*PaginatedResponse[ClanCapitalRaidSeason], error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, ClansEndpoint.Build(tag, "capitalraidseasons"), req, true)
+// GetClanCapitalRaidSeasonsCtx is like GetClanCapitalRaidSeasons, but uses ctx for the request.
+func (h *Client) GetClanCapitalRaidSeasonsCtx(ctx context.Context, tag Tag, params *PagingParams) (*PaginatedResponse[ClanCapitalRaidSeason], error) {
+	escaped, err := escapeTag(tag)
 	if err != nil {
 		return nil, err
 	}
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(ClansEndpoint, escaped, "capitalraidseasons"), req, true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[PaginatedResponse[ClanCapitalRaidSeason]](data, meta)
+}
 
-	var seasons *PaginatedResponse[ClanCapitalRaidSeason]
-	err = sonic.Unmarshal(data, &seasons)
-	return seasons, err
+// IterClanCapitalRaidSeasons is like GetClanCapitalRaidSeasons, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanCapitalRaidSeasons(ctx context.Context, tag Tag, params *PagingParams) iter.Seq2[ClanCapitalRaidSeason, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanCapitalRaidSeason], error) {
+		return h.GetClanCapitalRaidSeasonsCtx(ctx, tag, params)
+	}, params)
 }
@@ -0,0 +1,78 @@
+package goclash
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSearchClanParamsValidate(t *testing.T) {
+	tests := []struct {
+		params SearchClanParams
+		err    string
+	}{
+		{SearchClanParams{}, "at least one filter"},
+		{SearchClanParams{}.WithLimit(5), "at least one filter"},
+		{SearchClanParams{Name: "ab"}, "at least 3 characters"},
+		{SearchClanParams{WarFrequency: "sometimes"}, "invalid war frequency"},
+		{SearchClanParams{}.WithMembers(1, 0), "min members must be between"},
+		{SearchClanParams{}.WithMembers(30, 20), "must not exceed"},
+		{SearchClanParams{MinClanLevel: 1}, "min clan level"},
+		{SearchClanParams{}.WithWarFrequency(WarFrequencyUnknown), ""},
+		{SearchClanParams{Name: "abc"}, ""},
+		{SearchClanParams{}.WithLabels(Label{ID: 1}), ""},
+	}
+	for _, tt := range tests {
+		err := tt.params.Validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("Validate(%+v) = %v, want error containing %q", tt.params, err, tt.err)
+		}
+	}
+}
+
+func TestSearchClansQuery(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, `{"items":[]}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	params := SearchClanParams{}.
+		WithLocation(Location{ID: 32000094}).
+		WithLabels(Label{ID: 56000000}, Label{ID: 56000001}).
+		WithMembers(10, 0).
+		WithWarFrequency(WarFrequencyAlways).
+		WithLimit(5)
+	if _, err = client.SearchClans(params); err != nil {
+		t.Fatal(err)
+	}
+	want := "labelIds=56000000%2C56000001&limit=5&locationId=32000094&minMembers=10&warFrequency=always"
+	if query != want {
+		t.Fatalf("got query %q, want %q", query, want)
+	}
+
+	if _, err = client.SearchClans(SearchClanParams{Name: "x"}); err == nil {
+		t.Fatal("got no error for invalid params")
+	}
+}
+
+func TestClanWarOrient(t *testing.T) {
+	war := &ClanWar{Clan: WarClan{Tag: "#2QQ"}, Opponent: WarClan{Tag: "#2P0"}}
+	oriented, ok := war.Orient(" 2po")
+	if !ok || oriented.Clan.Tag != "#2P0" || oriented.Opponent.Tag != "#2QQ" {
+		t.Fatalf("Orient returned %+v, %v; want the war of #2P0", oriented, ok)
+	}
+	if _, ok = war.Orient("#2GG"); ok {
+		t.Fatal("Orient found a clan that is not part of the war")
+	}
+}
@@ -1,6 +1,20 @@
 package goclash
 
-// New creates a new clash client, using the provided credentials.
-func New(creds Credentials) (*Client, error) {
-	return newClient(creds)
+import "context"
+
+// New creates a new clash client, using the provided credentials. The client can be configured by passing options, like WithTimeout or WithCache.
+func New(creds Credentials, opts ...Option) (*Client, error) {
+	return NewCtx(context.Background(), creds, opts...)
+}
+
+// NewCtx is like New, but uses ctx for logging in and setting up the API keys.
+func NewCtx(ctx context.Context, creds Credentials, opts ...Option) (*Client, error) {
+	return newDevClient(ctx, creds, opts...)
+}
+
+// NewWithKeys creates a new clash client, using pre-provisioned API keys (the raw tokens) in a round-robin fashion.
+//
+// Unlike New, the client never contacts the developer portal or looks up its IP address, so no keys are created or revoked. If a key is not valid for the current IP address, requests fail with ErrInvalidIP.
+func NewWithKeys(keys []string, opts ...Option) (*Client, error) {
+	return newStaticClient(keys, opts...)
 }
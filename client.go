@@ -1,37 +1,98 @@
 package goclash
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/http/cookiejar"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/go-resty/resty/v2"
+	"golang.org/x/net/publicsuffix"
 )
 
 type Client struct {
-	accounts []*APIAccount
-	rc       *resty.Client
-	ipAddr   string
-	keyIndex APIKeyIndex
-	cache    *Cache
-	mu       sync.Mutex
+	accounts     []*APIAccount
+	rc           *resty.Client
+	ipAddr       string
+	keyIndex     APIKeyIndex
+	cache        *Cache
+	ownedStore   *MemoryStore // ownedStore is the store created for the client, which is closed by Client.Close.
+	baseURL      string
+	devBaseURL   string
+	ipLookupURL  string
+	headers      map[string]string
+	logger       *slog.Logger
+	keyPrefix    string // keyPrefix is the prefix of the names of the API keys managed by the client.
+	staticKeys   bool   // staticKeys is true if the keys were provided by the user, and must not be managed via the developer portal.
+	rateLimit    float64
+	rateBurst    int
+	maxRetries   int
+	sem          chan struct{} // sem limits the number of concurrent requests made by bulk methods.
+	revalidating sync.Map      // revalidating holds the cache keys currently refreshed in the background.
+	flights      flightGroup   // flights coalesces concurrent identical GET requests.
+	mu           sync.Mutex
 }
 
-var defaultHeaders = map[string]string{
-	"Accept":       "application/json",
-	"Content-Type": "application/json",
-	"User-Agent":   "goclash",
+const (
+	defaultUserAgent    = "goclash"
+	defaultKeyPrefix    = "goclash"
+	revalidationTimeout = 30 * time.Second
+)
+
+func newClient(opts ...Option) *Client {
+	o := defaultClientOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	client := &Client{
+		rc:          newRestyClient(o),
+		cache:       o.cache,
+		baseURL:     strings.TrimSuffix(o.baseURL, "/"),
+		devBaseURL:  strings.TrimSuffix(o.devBaseURL, "/"),
+		ipLookupURL: o.ipLookupURL,
+		headers: map[string]string{
+			"Accept":       "application/json",
+			"Content-Type": "application/json",
+			"User-Agent":   o.userAgent,
+		},
+		logger:     o.logger,
+		keyPrefix:  o.keyPrefix,
+		rateLimit:  o.rateLimit,
+		rateBurst:  o.rateBurst,
+		maxRetries: o.maxRetries,
+		sem:        make(chan struct{}, o.maxConcurrency),
+	}
+	switch {
+	case o.cacheStore != nil:
+		client.cache = newCacheWithStore(o.cacheStore)
+	case client.cache == nil && o.cacheLimits != nil:
+		client.ownedStore = NewMemoryStoreWithConfig(*o.cacheLimits)
+		client.cache = newCacheWithStore(client.ownedStore)
+	case client.cache == nil:
+		client.ownedStore = NewMemoryStore()
+		client.cache = newCacheWithStore(client.ownedStore)
+	}
+	if o.staleWhileRevalidate > 0 {
+		client.SetStaleWhileRevalidate(o.staleWhileRevalidate)
+	}
+	return client
 }
 
-func newClient(creds Credentials) (*Client, error) {
-	accounts := make([]*APIAccount, 0, len(creds))
+// newDevClient creates a client that manages the API keys of the accounts in creds via the developer portal.
+func newDevClient(ctx context.Context, creds Credentials, opts ...Option) (*Client, error) {
+	client := newClient(opts...)
+	client.accounts = make([]*APIAccount, 0, len(creds))
 	for email, password := range creds {
-		accounts = append(accounts, &APIAccount{
+		client.accounts = append(client.accounts, &APIAccount{
 			Credentials: &APIAccountCredentials{
 				Email:    email,
 				Password: password,
@@ -39,241 +100,445 @@ func newClient(creds Credentials) (*Client, error) {
 		})
 	}
 
-	client := &Client{
-		accounts: accounts,
-		rc:       resty.New(),
-		cache:    newCache(),
-	}
-
-	if err := client.updateIPAddr(); err != nil {
+	if err := client.updateIPAddr(ctx); err != nil {
 		return nil, err
 	}
-	if err := client.updateAccounts(); err != nil {
+	if err := client.updateAccounts(ctx); err != nil {
 		return nil, err
 	}
 
 	return client, nil
 }
 
-func (h *Client) do(method, url string, req *resty.Request, retry bool) ([]byte, error) {
-	if h.cache.enabled {
-		if data, ok := h.cache.Get(url); ok {
-			return data, nil
+// newStaticClient creates a client that only uses the given keys, grouped into accounts of keysPerAccount keys without credentials.
+func newStaticClient(keys []string, opts ...Option) (*Client, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no API keys were provided")
+	}
+
+	client := newClient(opts...)
+	client.staticKeys = true
+	for i, key := range keys {
+		if i%keysPerAccount == 0 {
+			client.accounts = append(client.accounts, &APIAccount{})
+		}
+		client.accounts[len(client.accounts)-1].Keys[i%keysPerAccount] = &APIKey{Key: key}
+	}
+	return client, nil
+}
+
+func newRestyClient(o *clientOptions) *resty.Client {
+	var rc *resty.Client
+	if o.httpClient != nil {
+		// work on a copy, so that clients sharing an *http.Client don't share a developer portal session
+		hc := *o.httpClient
+		if hc.Jar == nil {
+			hc.Jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 		}
+		rc = resty.NewWithClient(&hc)
+	} else {
+		rc = resty.New()
 	}
 
-	res, err := req.Execute(method, url)
+	if o.transport != nil {
+		rc.SetTransport(o.transport)
+	}
+	if o.timeout > 0 {
+		rc.SetTimeout(o.timeout)
+	}
+	return rc
+}
+
+// Close releases the resources of the client, like the background goroutine of its default cache. Caches and stores passed using options are not closed.
+func (h *Client) Close() error {
+	if h.ownedStore != nil {
+		return h.ownedStore.Close()
+	}
+	return nil
+}
+
+// do executes req, serving it from cache if possible. Concurrent GET requests for the same cache key are coalesced, so they share one request and one cache write.
+func (h *Client) do(ctx context.Context, method, url string, req *resty.Request, retry bool) ([]byte, ResponseMeta, error) {
+	if method != http.MethodGet {
+		return h.send(ctx, method, url, req, retry)
+	}
+
+	key := cacheKey(url, req)
+	for {
+		body, meta, shared, err := h.flights.do(ctx, key, func() ([]byte, ResponseMeta, error) {
+			return h.send(ctx, method, url, req, retry)
+		})
+		// the request we waited for was canceled by its caller, but ours is still wanted
+		if shared && isContextError(err) && ctx.Err() == nil {
+			continue
+		}
+		return body, meta, err
+	}
+}
+
+// send executes req without coalescing, serving it from cache if possible. The request is bound to ctx, which is also honored when refreshing the IP address and API keys before retrying.
+//
+// Expired cache entries are revalidated using a conditional request, or returned right away and refreshed in the background if Cache.staleWhileRevalidate allows it.
+func (h *Client) send(ctx context.Context, method, url string, req *resty.Request, retry bool) ([]byte, ResponseMeta, error) {
+	key := cacheKey(url, req)
+	cacheable := method == http.MethodGet && h.cache.isEnabled()
+	var stale *cacheEntry
+	if cacheable {
+		if entry, ok := h.cache.getEntry(key); ok {
+			switch {
+			case entry.isFresh():
+				h.cache.hits.Add(1)
+				return entry.body, entry.meta(true), nil
+			case h.cache.canServeStale(entry):
+				h.cache.hits.Add(1)
+				meta := entry.meta(true)
+				h.revalidateInBackground(ctx, key, url, req, entry)
+				return entry.body, meta, nil
+			case entry.hasValidators():
+				stale = entry
+				stale.setConditionalHeaders(req)
+			}
+		}
+	}
+
+	res, err := h.execute(ctx, method, url, req)
 	if err != nil {
-		return nil, err
+		return nil, ResponseMeta{}, err
 	}
 
-	if res.StatusCode() < 300 {
-		h.cache.CacheResponse(url, res)
-		return res.Body(), nil
+	if stale != nil && res.StatusCode() == http.StatusNotModified {
+		h.cache.hits.Add(1)
+		h.cache.refresh(key, stale, res)
+		return stale.body, stale.meta(true), nil
+	}
+	if cacheable {
+		h.cache.misses.Add(1)
 	}
 
-	clientErr := &ClientError{Status: res.StatusCode(), APIError: &APIError{}}
-	if err = sonic.Unmarshal(res.Body(), &clientErr.APIError); err != nil {
-		return nil, err
+	if res.StatusCode() < 300 {
+		entry, ok := h.cache.newEntry(res)
+		if cacheable && ok {
+			h.cache.setEntry(key, entry)
+		}
+		return res.Body(), entry.meta(false), nil
 	}
+
+	clientErr := newClientError(url, res)
 	if res.StatusCode() == http.StatusForbidden {
 		if !retry {
-			return nil, clientErr
+			return nil, ResponseMeta{}, clientErr
 		}
 
-		if clientErr.APIError.Reason == ReasonInvalidIP {
-			if err = h.updateIPAddr(); err != nil {
-				return nil, err
+		if clientErr.APIError.Reason == ReasonInvalidIP && !h.staticKeys {
+			h.logger.Warn("API key rejected for IP address, updating keys", "ip", h.currentIPAddr())
+			if err = h.updateIPAddr(ctx); err != nil {
+				return nil, ResponseMeta{}, err
+			}
+			if err = h.updateAccounts(ctx); err != nil {
+				return nil, ResponseMeta{}, err
 			}
-			if err = h.updateAccounts(); err != nil {
-				return nil, err
+			if err = ctx.Err(); err != nil {
+				return nil, ResponseMeta{}, err
 			}
-			return h.do(method, url, req, false)
+			return h.send(ctx, method, url, req, false)
 		}
 	}
 
-	return nil, clientErr
+	return nil, ResponseMeta{}, clientErr
+}
+
+// revalidateInBackground refreshes a stale cache entry without blocking the caller, using a conditional request if possible.
+// Only one refresh per key runs at a time, and it is coalesced with requests for the same key via h.flights. The refresh is not canceled together with ctx, but keeps its values.
+func (h *Client) revalidateInBackground(ctx context.Context, key, url string, req *resty.Request, entry *cacheEntry) {
+	if _, running := h.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	// the caller keeps using entry, so the refresh works on a copy
+	stale := *entry
+	go func() {
+		defer h.revalidating.Delete(key)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revalidationTimeout)
+		defer cancel()
+
+		_, _, _, err := h.flights.do(ctx, key, func() ([]byte, ResponseMeta, error) {
+			return h.revalidate(ctx, key, url, req, &stale)
+		})
+		if err != nil {
+			h.logger.Debug("failed to revalidate cached response", "url", url, "error", err)
+		}
+	}()
+}
+
+// revalidate refreshes a stale cache entry, using a conditional request if possible.
+func (h *Client) revalidate(ctx context.Context, key, url string, req *resty.Request, stale *cacheEntry) ([]byte, ResponseMeta, error) {
+	stale.setConditionalHeaders(req)
+	res, err := h.execute(ctx, http.MethodGet, url, req)
+	switch {
+	case err != nil:
+		return nil, ResponseMeta{}, err
+	case res.StatusCode() == http.StatusNotModified:
+		h.cache.refresh(key, stale, res)
+		return stale.body, stale.meta(true), nil
+	case res.StatusCode() < 300:
+		entry, ok := h.cache.newEntry(res)
+		if ok {
+			h.cache.setEntry(key, entry)
+		}
+		return res.Body(), entry.meta(false), nil
+	}
+	return nil, ResponseMeta{}, newClientError(url, res)
+}
+
+// execute sends req using the next API key, once the key's rate limiter allows it. Throttled requests are retried up to maxRetries times,
+// pausing the key that was throttled.
+func (h *Client) execute(ctx context.Context, method, url string, req *resty.Request) (*resty.Response, error) {
+	for attempt := 0; ; attempt++ {
+		key := h.getKey()
+		if key == nil {
+			return nil, notSent(attempt, errors.New("no API key available"))
+		}
+		if err := key.limiter.wait(ctx); err != nil {
+			return nil, notSent(attempt, err)
+		}
+
+		res, err := req.SetContext(ctx).SetAuthToken(key.Key).Execute(method, url)
+		if err != nil {
+			return nil, err
+		}
+		if !isRetryableStatus(res.StatusCode()) || attempt >= h.maxRetries {
+			return res, nil
+		}
+
+		delay := retryDelay(res.Header(), attempt)
+		key.limiter.pause(delay)
+		h.logger.Warn("request throttled, retrying", "url", url, "status", res.StatusCode(), "delay", delay)
+		if err = sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
-func (h *Client) updateIPAddr() error {
-	res, err := h.rc.R().Get(IPifyEndpoint)
+func (h *Client) updateIPAddr(ctx context.Context) error {
+	res, err := h.rc.R().SetContext(ctx).Get(h.ipLookupURL)
 	if err != nil {
 		return err
 	}
 
 	body := string(res.Body())
 	if res.StatusCode() != http.StatusOK {
-		return errors.New(body)
+		return fmt.Errorf("looking up IP address failed with status %d: %s", res.StatusCode(), body)
 	}
 	if body == "" {
 		return errors.New("couldn't get IP address")
 	}
-	if body == h.ipAddr {
-		return nil
-	}
-
 	h.mu.Lock()
+	changed := body != h.ipAddr
 	h.ipAddr = body
 	h.mu.Unlock()
+	if changed {
+		h.logger.Info("updated IP address", "ip", body)
+	}
 	return nil
 }
 
-func (h *Client) login(account *APIAccount) error {
-	res, err := h.newDefaultRequest().SetBody(account.Credentials).Post(DevLoginEndpoint.URL())
+// currentIPAddr returns the public IP address the API keys are created for.
+func (h *Client) currentIPAddr() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.ipAddr
+}
+
+func (h *Client) login(ctx context.Context, account *APIAccount) error {
+	res, err := h.newDefaultRequest().SetContext(ctx).SetBody(account.Credentials).Post(DevLoginEndpoint.URLFrom(h.devBaseURL))
 	if err != nil {
 		return err
 	}
 
 	if res.StatusCode() != http.StatusOK {
-		return errors.New(string(res.Body()))
+		return newDevPortalError(DevPortalLogin, account, res)
 	}
 
 	return sonic.Unmarshal(res.Body(), &account)
 }
 
-func (h *Client) updateAccounts() error {
+func (h *Client) updateAccounts(ctx context.Context) error {
+	var hasKeys bool
 	for _, account := range h.accounts {
-		if err := h.login(account); err != nil {
+		if err := h.login(ctx, account); err != nil {
 			return err
 		}
-		if err := h.updateAccountKeys(account); err != nil {
+		if err := h.updateAccountKeys(ctx, account); err != nil {
 			return err
 		}
+		hasKeys = hasKeys || account.Keys[0] != nil
 	}
 
+	if !hasKeys {
+		return fmt.Errorf("no API keys available: all key slots are used by keys whose name doesn't start with %q", h.keyPrefix)
+	}
 	return nil
 }
 
-// getAccountKeys retrieves the API keys for the given account and sets APIAccount.Keys.
-func (h *Client) getAccountKeys(account *APIAccount) error {
-	res, err := h.newDefaultRequest().Post(DevKeyListEndpoint.URL())
+// getAccountKeys retrieves all API keys of the account that is currently logged in.
+func (h *Client) getAccountKeys(ctx context.Context, account *APIAccount) ([]*APIKey, error) {
+	res, err := h.newDefaultRequest().SetContext(ctx).Post(DevKeyListEndpoint.URLFrom(h.devBaseURL))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if res.StatusCode() != http.StatusOK {
-		return errors.New(string(res.Body()))
+		return nil, newDevPortalError(DevPortalListKeys, account, res)
 	}
 
 	var body *KeyListResponse
 	if err = sonic.Unmarshal(res.Body(), &body); err != nil {
-		return err
-	}
-
-	h.mu.Lock()
-	for i := range body.Keys {
-		account.Keys[i] = body.Keys[i]
+		return nil, err
 	}
-	h.mu.Unlock()
-	return nil
+	return body.Keys, nil
 }
 
-func (h *Client) updateAccountKeys(account *APIAccount) error {
-	if err := h.getAccountKeys(account); err != nil {
+// updateAccountKeys sets APIAccount.Keys to as many keys valid for the current IP address as the account can hold.
+//
+// Only keys whose name starts with the client's key name prefix (see WithKeyNamePrefix) are managed: those valid for the current IP address are reused, and those for other IP addresses are revoked.
+// All other keys, e.g. created by other machines using a different key name, are left untouched, but still count towards the account's key limit.
+func (h *Client) updateAccountKeys(ctx context.Context, account *APIAccount) error {
+	keys, err := h.getAccountKeys(ctx, account)
+	if err != nil {
 		return err
 	}
 
+	ipAddr := h.currentIPAddr()
+	var reused, stale []*APIKey
+	var foreign int
+	for _, key := range keys {
+		switch {
+		case !strings.HasPrefix(key.Name, h.keyPrefix):
+			foreign++
+		case slices.Contains(key.CidrRanges, ipAddr):
+			reused = append(reused, key)
+		default:
+			stale = append(stale, key)
+		}
+	}
+
 	errChan := make(chan error, keysPerAccount)
-	var freeKeyIndexes []int
 	var wg sync.WaitGroup
-	for i := 0; i < keysPerAccount; i++ {
-		if account.Keys[i] == nil {
-			freeKeyIndexes = append(freeKeyIndexes, i)
-			continue
-		}
-		if !slices.Contains(account.Keys[i].CidrRanges, h.ipAddr) {
-			wg.Add(1)
-			go func(key *APIKey, i int) {
-				defer wg.Done()
-				if err := h.revokeAccountKey(key); err != nil {
-					errChan <- err
-					return
-				}
-				if err := h.createAccountKey(account, i); err != nil {
-					errChan <- err
-					return
-				}
-			}(account.Keys[i], i)
-		}
+	for _, key := range stale {
+		wg.Add(1)
+		go func(key *APIKey) {
+			defer wg.Done()
+			if err := h.revokeAccountKey(ctx, account, key); err != nil {
+				errChan <- err
+			}
+		}(key)
 	}
 	wg.Wait()
+	if len(errChan) > 0 {
+		return <-errChan
+	}
 
-	for i := range freeKeyIndexes {
+	var accountKeys [keysPerAccount]*APIKey
+	copy(accountKeys[:], reused)
+	for i := len(reused); i < keysPerAccount-foreign; i++ {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			if err := h.createAccountKey(account, i); err != nil {
+			key, err := h.createAccountKey(ctx, account)
+			if err != nil {
 				errChan <- err
+				return
 			}
+			accountKeys[i] = key
 		}(i)
 	}
 	wg.Wait()
-
 	if len(errChan) > 0 {
 		return <-errChan
 	}
+
+	h.mu.Lock()
+	account.Keys = accountKeys
+	h.mu.Unlock()
+	h.logger.Info("updated API keys", "email", account.Credentials.Email, "reused", len(reused), "revoked", len(stale), "foreign", foreign)
 	return nil
 }
 
-func (h *Client) createAccountKey(account *APIAccount, index int) error {
+func (h *Client) createAccountKey(ctx context.Context, account *APIAccount) (*APIKey, error) {
 	desc := fmt.Sprintf("Created at %s by goclash", time.Now().UTC().Round(time.Minute).String())
 	key := &APIKey{
-		Name:        "goclash",
+		Name:        h.keyPrefix,
 		Description: desc,
-		CidrRanges:  []string{h.ipAddr},
+		CidrRanges:  []string{h.currentIPAddr()},
 		Scopes:      []string{"clash"},
 	}
-	res, err := h.newDefaultRequest().SetBody(key).Post(DevKeyCreateEndpoint.URL())
+	res, err := h.newDefaultRequest().SetContext(ctx).SetBody(key).Post(DevKeyCreateEndpoint.URLFrom(h.devBaseURL))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if res.StatusCode() != http.StatusOK {
-		return errors.New(string(res.Body()))
+		return nil, newDevPortalError(DevPortalCreateKey, account, res)
 	}
 
 	var keyRes *CreateKeyResponse
 	if err = sonic.Unmarshal(res.Body(), &keyRes); err != nil {
-		return err
+		return nil, err
 	}
-
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	account.Keys[index] = keyRes.Key
-	return nil
+	h.logger.Info("created API key", "id", keyRes.Key.ID)
+	return keyRes.Key, nil
 }
 
-func (h *Client) revokeAccountKey(key *APIKey) error {
+func (h *Client) revokeAccountKey(ctx context.Context, account *APIAccount, key *APIKey) error {
 	payload := map[string]string{"id": key.ID}
-	res, err := h.newDefaultRequest().SetBody(payload).Post(DevKeyRevokeEndpoint.URL())
+	res, err := h.newDefaultRequest().SetContext(ctx).SetBody(payload).Post(DevKeyRevokeEndpoint.URLFrom(h.devBaseURL))
 	if err != nil {
 		return err
 	}
 
 	if res.StatusCode() != http.StatusOK {
-		return errors.New(string(res.Body()))
+		return newDevPortalError(DevPortalRevokeKey, account, res)
 	}
+	h.logger.Info("revoked API key", "id", key.ID)
 	return nil
 }
 
-func (h *Client) getKey() string {
+// getKey returns the next API key in a round-robin fashion, skipping empty key slots. It returns nil if there are no keys.
+func (h *Client) getKey() *APIKey {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	key := h.accounts[h.keyIndex.AccountIndex].Keys[h.keyIndex.KeyIndex]
-	if h.keyIndex.KeyIndex == len(h.accounts[h.keyIndex.AccountIndex].Keys)-1 {
-		h.keyIndex.AccountIndex = (h.keyIndex.AccountIndex + 1) % len(h.accounts)
+	for i := 0; i < len(h.accounts)*keysPerAccount; i++ {
+		key := h.accounts[h.keyIndex.AccountIndex].Keys[h.keyIndex.KeyIndex]
+		if h.keyIndex.KeyIndex == len(h.accounts[h.keyIndex.AccountIndex].Keys)-1 {
+			h.keyIndex.AccountIndex = (h.keyIndex.AccountIndex + 1) % len(h.accounts)
+		}
+		h.keyIndex.KeyIndex = (h.keyIndex.KeyIndex + 1) % len(h.accounts[h.keyIndex.AccountIndex].Keys)
+		if key != nil {
+			if key.limiter == nil {
+				key.limiter = newRateLimiter(h.rateLimit, h.rateBurst)
+			}
+			return key
+		}
 	}
-	h.keyIndex.KeyIndex = (h.keyIndex.KeyIndex + 1) % len(h.accounts[h.keyIndex.AccountIndex].Keys)
-	return key.Key
+	return nil
 }
 
 func (h *Client) newDefaultRequest() *resty.Request {
-	return h.rc.R().SetHeaders(defaultHeaders)
+	return h.rc.R().SetHeaders(h.headers)
+}
+
+// cacheKey returns the key a response to req is cached under, which is the URL including the query parameters (e.g. paging cursors).
+func cacheKey(url string, req *resty.Request) string {
+	if query := req.QueryParam.Encode(); query != "" {
+		return url + "?" + query
+	}
+	return url
 }
 
-func (h *Client) withAuth(req *resty.Request) *resty.Request {
-	return req.SetAuthToken(h.getKey())
+// buildURL returns the full URL for the endpoint, using the base URL of the client.
+func (h *Client) buildURL(e Endpoint, routes ...string) string {
+	return e.BuildFrom(h.baseURL, routes...)
 }
 
 func (h *Client) withPaging(r *resty.Request, params *PagingParams) *resty.Request {
@@ -0,0 +1,322 @@
+package goclash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewWithKeysRoundRobin(t *testing.T) {
+	var mu sync.Mutex
+	var tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			t.Errorf("unexpected developer portal request to %s", r.URL.Path)
+		}
+		mu.Lock()
+		tokens = append(tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		mu.Unlock()
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	keys := make([]string, keysPerAccount+2)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	client, err := NewWithKeys(keys, WithBaseURL(srv.URL), WithDevBaseURL(srv.URL), WithIPLookupURL(srv.URL+"/api/ip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2*len(keys); i++ {
+		if _, err = client.GetPlayer(TagFromID(uint32(i), 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, token := range tokens {
+		if want := keys[i%len(keys)]; token != want {
+			t.Fatalf("request %d used key %q, want %q", i, token, want)
+		}
+	}
+}
+
+func TestNewWithKeysEmpty(t *testing.T) {
+	if _, err := NewWithKeys(nil); err == nil {
+		t.Fatal("expected error for empty keys")
+	}
+}
+
+func TestNewLeavesForeignKeys(t *testing.T) {
+	const ip = "1.2.3.4"
+	var mu sync.Mutex
+	var revoked []string
+	var created int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/ip":
+			fmt.Fprint(w, ip)
+		case string(DevLoginEndpoint):
+			fmt.Fprint(w, `{}`)
+		case string(DevKeyListEndpoint):
+			fmt.Fprint(w, `{"keys":[
+				{"id":"own-current","name":"bot-a","key":"a1","cidrRanges":["`+ip+`"]},
+				{"id":"own-stale","name":"bot-a","key":"a2","cidrRanges":["5.6.7.8"]},
+				{"id":"own-prefixed","name":"bot-a-2","key":"a3","cidrRanges":["5.6.7.8"]},
+				{"id":"foreign","name":"bot-b","key":"b1","cidrRanges":["5.6.7.8"]}
+			]}`)
+		case string(DevKeyRevokeEndpoint):
+			var body struct{ ID string }
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			revoked = append(revoked, body.ID)
+			fmt.Fprint(w, `{}`)
+		case string(DevKeyCreateEndpoint):
+			created++
+			fmt.Fprintf(w, `{"key":{"id":"new%d","name":"bot-a","key":"new%d","cidrRanges":["%s"]}}`, created, created, ip)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := New(Credentials{"email": "password"}, WithDevBaseURL(srv.URL), WithIPLookupURL(srv.URL+"/ip"), WithKeyNamePrefix("bot-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(revoked)
+	if len(revoked) != 2 || revoked[0] != "own-prefixed" || revoked[1] != "own-stale" {
+		t.Fatalf("revoked %v, want only own-prefixed and own-stale", revoked)
+	}
+	if want := keysPerAccount - 2; created != want {
+		t.Fatalf("created %d keys, want %d", created, want)
+	}
+	keys := client.accounts[0].Keys
+	if keys[0].ID != "own-current" {
+		t.Fatalf("first key is %q, want reused key own-current", keys[0].ID)
+	}
+	if keys[keysPerAccount-1] != nil {
+		t.Fatal("slot of foreign key must stay empty")
+	}
+}
+
+func TestRetryOnTooManyRequests(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"reason":"requestThrottled","message":"slow down"}`)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithRateLimit(100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	player, err := client.GetPlayer("#2PP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Name != "test" || requests != 3 {
+		t.Fatalf("got player %q after %d requests, want test after 3", player.Name, requests)
+	}
+}
+
+func TestConcurrentRequestsAreCoalesced(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, `{"state":"inWar","teamSize":15}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			war, err := client.GetCurrentClanWar("#2PP")
+			if err == nil && war.TeamSize != 15 {
+				err = fmt.Errorf("got team size %d, want 15", war.TeamSize)
+			}
+			errs <- err
+		}()
+	}
+	// give every goroutine time to join the request in flight
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("got %d requests, want 1", n)
+	}
+}
+
+func TestCanceledContextAbortsRequest(t *testing.T) {
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err = client.GetPlayerCtx(ctx, "#2PP"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetPlayerCtx returned %v, want context.Canceled", err)
+	}
+}
+
+func TestDeadlineStopsInvalidIPRecovery(t *testing.T) {
+	var ipLookups, logins, requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ip":
+			// the first lookup is made by New, the second one hangs until the caller gives up
+			if ipLookups.Add(1) > 1 {
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, "1.2.3.4")
+		case string(DevLoginEndpoint):
+			logins.Add(1)
+			fmt.Fprint(w, `{}`)
+		case string(DevKeyListEndpoint):
+			fmt.Fprint(w, `{"keys":[]}`)
+		case string(DevKeyCreateEndpoint):
+			fmt.Fprint(w, `{"key":{"id":"id","name":"goclash","key":"key","cidrRanges":["1.2.3.4"]}}`)
+		default:
+			requests.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"reason":"accessDenied.invalidIp","message":"Invalid authorization"}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := New(Credentials{"email": "password"}, WithBaseURL(srv.URL), WithDevBaseURL(srv.URL), WithIPLookupURL(srv.URL+"/ip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = client.GetPlayerCtx(ctx, "#2PP"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetPlayerCtx returned %v, want context.DeadlineExceeded", err)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Fatalf("logged in %d times, want only the login of New", n)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("got %d API requests, want 1", n)
+	}
+}
+
+func TestCanceledFlightLeaderDoesNotFailWaiters(t *testing.T) {
+	var requests atomic.Int32
+	leaderStarted := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(leaderStarted)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, `{"tag":"#2PP","name":"test"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetPlayerCtx(ctx, "#2PP")
+		leaderErr <- err
+	}()
+	<-leaderStarted
+
+	waiterErr := make(chan error, 1)
+	go func() {
+		player, err := client.GetPlayer("#2PP")
+		if err == nil && player.Name != "test" {
+			err = fmt.Errorf("got player %q, want test", player.Name)
+		}
+		waiterErr <- err
+	}()
+	// give the waiter time to join the request in flight
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader returned %v, want context.Canceled", err)
+	}
+	if err := <-waiterErr; err != nil {
+		t.Fatalf("waiter returned %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("got %d requests, want 2", n)
+	}
+}
+
+func TestWithHTTPClientIsNotModified(t *testing.T) {
+	hc := &http.Client{}
+	for range 2 {
+		client, err := NewWithKeys([]string{"key"}, WithHTTPClient(hc), WithTransport(http.DefaultTransport))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Close()
+	}
+	if hc.Jar != nil || hc.Transport != nil {
+		t.Fatal("the *http.Client passed to WithHTTPClient was modified")
+	}
+}
@@ -0,0 +1,144 @@
+package goclash
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// ClanWarLeague is a clan war league group, together with the wars of all its rounds.
+type ClanWarLeague struct {
+	Group *ClanWarLeagueGroup
+	// Rounds holds the wars of every round, in the order of Group.Rounds. Wars that were not drawn yet, or failed to be fetched, are left out.
+	Rounds [][]*ClanWarLeagueWar
+}
+
+// ClanWarLeagueStanding is the standing of a clan in the table of a ClanWarLeague.
+type ClanWarLeagueStanding struct {
+	Rank int
+	Clan ClanWarLeagueClan
+	// Stars is the total of stars, including 10 bonus stars for every war won.
+	Stars int
+	// Destruction is the total destruction, which is the destruction percentage of every war multiplied by its team size.
+	Destruction float64
+	Wins        int
+	Ties        int
+	Losses      int
+	Attacks     int
+}
+
+// clanWarLeagueWinBonus are the stars a clan is awarded for winning a clan war league war.
+const clanWarLeagueWinBonus = 10
+
+// GetCurrentClanWarLeague returns the current clan war league group of a clan, together with all of its wars, which are fetched concurrently.
+// If some wars failed to be fetched, the league is returned together with an error joining a *TagError for each of them.
+//
+// GET /clans/{clanTag}/currentwar/leaguegroup and GET /clanwarleagues/wars/{warTag}
+func (h *Client) GetCurrentClanWarLeague(tag Tag) (*ClanWarLeague, error) {
+	return h.GetCurrentClanWarLeagueCtx(context.Background(), tag)
+}
+
+// GetCurrentClanWarLeagueCtx is like GetCurrentClanWarLeague, but uses ctx for the requests.
+func (h *Client) GetCurrentClanWarLeagueCtx(ctx context.Context, tag Tag) (*ClanWarLeague, error) {
+	group, err := h.GetCurrentClanWarLeagueGroupCtx(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var warTags []Tag
+	for _, round := range group.Rounds {
+		for _, warTag := range round.WarTags {
+			if warTag != "#0" {
+				warTags = append(warTags, Tag(warTag))
+			}
+		}
+	}
+	wars := collect(fetchEach(ctx, h, warTags, h.GetClanWarLeagueWarCtx), len(warTags))
+
+	league := &ClanWarLeague{Group: group, Rounds: make([][]*ClanWarLeagueWar, len(group.Rounds))}
+	i := 0
+	for round := range group.Rounds {
+		for _, warTag := range group.Rounds[round].WarTags {
+			if warTag == "#0" {
+				continue
+			}
+			if war := wars[i].Value; war != nil {
+				league.Rounds[round] = append(league.Rounds[round], war)
+			}
+			i++
+		}
+	}
+	return league, wars.Err()
+}
+
+// War returns the war of the clan with the given tag in the round with the given index, oriented so the clan is ClanWar.Clan, or nil if it is not known.
+func (l *ClanWarLeague) War(round int, clanTag Tag) *ClanWarLeagueWar {
+	if round < 0 || round >= len(l.Rounds) {
+		return nil
+	}
+	for _, war := range l.Rounds[round] {
+		if oriented, ok := war.Orient(clanTag); ok {
+			return oriented
+		}
+	}
+	return nil
+}
+
+// Wars returns the war of the clan with the given tag in every round, oriented like War. Wars that are not known are nil.
+func (l *ClanWarLeague) Wars(clanTag Tag) []*ClanWarLeagueWar {
+	wars := make([]*ClanWarLeagueWar, len(l.Rounds))
+	for round := range l.Rounds {
+		wars[round] = l.War(round, clanTag)
+	}
+	return wars
+}
+
+// Table returns the standings of all clans, ordered by stars and then destruction, like the in-game table.
+// Stars and destruction of wars in progress are included, while wins, ties and losses only count once a war ended.
+func (l *ClanWarLeague) Table() []ClanWarLeagueStanding {
+	standings := make(map[string]*ClanWarLeagueStanding, len(l.Group.Clans))
+	for _, clan := range l.Group.Clans {
+		standings[clan.Tag] = &ClanWarLeagueStanding{Clan: clan}
+	}
+
+	for _, wars := range l.Rounds {
+		for _, war := range wars {
+			for _, side := range []struct{ clan, opponent *WarClan }{{&war.Clan, &war.Opponent}, {&war.Opponent, &war.Clan}} {
+				standing, ok := standings[side.clan.Tag]
+				if !ok {
+					continue
+				}
+				standing.Stars += side.clan.Stars
+				standing.Destruction += side.clan.DestructionPercentage * float64(war.TeamSize)
+				standing.Attacks += side.clan.Attacks
+				if war.State != ClanWarStateWarEnded {
+					continue
+				}
+				switch warResult(side.clan, side.opponent) {
+				case ClanWarResultWin:
+					standing.Wins++
+					standing.Stars += clanWarLeagueWinBonus
+				case ClanWarResultTie:
+					standing.Ties++
+				default:
+					standing.Losses++
+				}
+			}
+		}
+	}
+
+	table := make([]ClanWarLeagueStanding, 0, len(standings))
+	for _, clan := range l.Group.Clans {
+		table = append(table, *standings[clan.Tag])
+	}
+	slices.SortStableFunc(table, func(a, b ClanWarLeagueStanding) int {
+		if c := cmp.Compare(b.Stars, a.Stars); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Destruction, a.Destruction)
+	})
+	for i := range table {
+		table[i].Rank = i + 1
+	}
+	return table
+}
@@ -0,0 +1,73 @@
+package goclash
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetCurrentClanWarLeague(t *testing.T) {
+	wars := map[string]string{
+		"#9PP": `{"state":"warEnded","teamSize":15,"attacksPerMember":1,"warStartTime":"20240102T080000.000Z","clan":{"tag":"#2PP","stars":30,"destructionPercentage":80,"attacks":14},"opponent":{"tag":"#2QQ","stars":25,"destructionPercentage":70,"attacks":15}}`,
+		"#9YY": `{"state":"inWar","teamSize":15,"clan":{"tag":"#2RR","stars":20,"destructionPercentage":50,"attacks":10},"opponent":{"tag":"#2GG","stars":22,"destructionPercentage":60,"attacks":11}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/currentwar/leaguegroup"):
+			fmt.Fprint(w, `{"state":"inWar","season":"2024-01","clans":[{"tag":"#2PP","name":"a"},{"tag":"#2QQ","name":"b"},{"tag":"#2GG","name":"c"},{"tag":"#2RR","name":"d"}],`+
+				`"rounds":[{"warTags":["#9PP","#9YY"]},{"warTags":["#0","#0"]}]}`)
+		case strings.HasPrefix(r.URL.Path, "/clanwarleagues/wars/"):
+			war, ok := wars[strings.TrimPrefix(r.URL.Path, "/clanwarleagues/wars/")]
+			if !ok {
+				t.Errorf("unexpected request for %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"reason":"notFound"}`)
+				return
+			}
+			fmt.Fprint(w, war)
+		default:
+			t.Errorf("unexpected request for %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	league, err := client.GetCurrentClanWarLeague("#2PP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(league.Rounds) != 2 || len(league.Rounds[0]) != 2 || len(league.Rounds[1]) != 0 {
+		t.Fatalf("got rounds with %d wars, want [2 0]", len(league.Rounds))
+	}
+	if war := league.War(0, "#2GG"); war == nil || war.Clan.Tag != "#2GG" || war.Opponent.Tag != "#2RR" {
+		t.Fatalf("got war %v for #2GG in round 0, want war against #2RR", war)
+	}
+	if war := league.War(0, "#2PP"); war.AttacksPerMember != 1 || war.WarStartTime.String() != "20240102T080000.000Z" || war.FetchedAt.IsZero() {
+		t.Fatalf("got war %+v, want attacksPerMember, warStartTime and meta", war)
+	}
+	if wars := league.Wars("#2QQ"); len(wars) != 2 || wars[0] == nil || wars[1] != nil {
+		t.Fatalf("got wars %v for #2QQ, want one war in round 0", wars)
+	}
+
+	var got []string
+	for _, standing := range league.Table() {
+		got = append(got, fmt.Sprintf("%d %s %d %.0f %d-%d-%d", standing.Rank, standing.Clan.Name, standing.Stars, standing.Destruction, standing.Wins, standing.Ties, standing.Losses))
+	}
+	want := []string{
+		"1 a 40 1200 1-0-0",
+		"2 b 25 1050 0-0-1",
+		"3 c 22 900 0-0-0",
+		"4 d 20 750 0-0-0",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got table\n%q\nwant\n%q", got, want)
+	}
+}
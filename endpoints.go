@@ -6,7 +6,12 @@ type Endpoint string
 //
 // Example: PlayersEndpoint.Build("ABC123") returns "https://api.clashofclans.com/v1/players/ABC123"
 func (e Endpoint) Build(routes ...string) string {
-	url := BaseURL + string(e)
+	return e.BuildFrom(BaseURL, routes...)
+}
+
+// BuildFrom is like Build, but uses baseURL instead of BaseURL.
+func (e Endpoint) BuildFrom(baseURL string, routes ...string) string {
+	url := baseURL + string(e)
 	for _, route := range routes {
 		url += "/" + route
 	}
@@ -30,7 +35,12 @@ const (
 type DevEndpoint string
 
 func (e DevEndpoint) URL() string {
-	return DevBaseURL + string(e)
+	return e.URLFrom(DevBaseURL)
+}
+
+// URLFrom is like URL, but uses devBaseURL instead of DevBaseURL.
+func (e DevEndpoint) URLFrom(devBaseURL string) string {
+	return devBaseURL + string(e)
 }
 
 const (
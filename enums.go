@@ -0,0 +1,351 @@
+package goclash
+
+import "github.com/bytedance/sonic"
+
+// The enum types below unmarshal values unknown to this package, e.g. ones added in a game update, to their Unknown member.
+// IsValid reports whether a value is known, which is false for the Unknown member. WarFrequency is the exception: the API returns "unknown"
+// for clans that didn't set their war frequency, so WarFrequencyUnknown is valid, and unknown values unmarshal to WarFrequencyUnrecognized instead.
+
+// WarFrequency is the war frequency of a clan, or a filter for SearchClanParams.
+type WarFrequency string
+
+// WarFrequencyUnrecognized is the value unknown war frequencies unmarshal to. It is not valid, and doesn't filter SearchClanParams.
+const WarFrequencyUnrecognized WarFrequency = ""
+
+const (
+	WarFrequencyUnknown       WarFrequency = "unknown"
+	WarFrequencyAlways        WarFrequency = "always"
+	WarFrequencyMTOncePerWeek WarFrequency = "moreThanOncePerWeek"
+	WarFrequencyOncePerWeek   WarFrequency = "oncePerWeek"
+	WarFrequencyLTOncePerWeek WarFrequency = "lessThanOncePerWeek"
+	WarFrequencyNever         WarFrequency = "never"
+	WarFrequencyAny           WarFrequency = "any"
+)
+
+var warFrequencyNames = map[WarFrequency]string{
+	WarFrequencyUnknown:       "Unknown",
+	WarFrequencyAlways:        "Always",
+	WarFrequencyMTOncePerWeek: "More Than Once Per Week",
+	WarFrequencyOncePerWeek:   "Once Per Week",
+	WarFrequencyLTOncePerWeek: "Less Than Once Per Week",
+	WarFrequencyNever:         "Never",
+	WarFrequencyAny:           "Any",
+}
+
+// IsValid reports whether w is a known WarFrequency, including WarFrequencyUnknown.
+func (w WarFrequency) IsValid() bool {
+	_, ok := warFrequencyNames[w]
+	return ok
+}
+
+func (w WarFrequency) String() string {
+	return string(w)
+}
+
+// Format returns a human-readable name, or an empty string if w is not a WarFrequency.
+func (w WarFrequency) Format() string {
+	return warFrequencyNames[w]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to WarFrequencyUnrecognized.
+func (w *WarFrequency) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, w, WarFrequencyUnrecognized)
+}
+
+// ClanType is the type of a clan, which determines who can join it.
+type ClanType string
+
+const (
+	ClanTypeUnknown    ClanType = "unknown"
+	ClanTypeOpen       ClanType = "open"
+	ClanTypeInviteOnly ClanType = "inviteOnly"
+	ClanTypeClosed     ClanType = "closed"
+)
+
+var clanTypeNames = map[ClanType]string{
+	ClanTypeUnknown:    "Unknown",
+	ClanTypeOpen:       "Anyone Can Join",
+	ClanTypeInviteOnly: "Invite Only",
+	ClanTypeClosed:     "Closed",
+}
+
+// IsValid reports whether c is a known ClanType, other than ClanTypeUnknown.
+func (c ClanType) IsValid() bool {
+	_, ok := clanTypeNames[c]
+	return ok && c != ClanTypeUnknown
+}
+
+func (c ClanType) String() string {
+	return string(c)
+}
+
+// Format returns a human-readable name, or an empty string if c is not a ClanType.
+func (c ClanType) Format() string {
+	return clanTypeNames[c]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to ClanTypeUnknown.
+func (c *ClanType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ClanTypeUnknown)
+}
+
+// Village is the village an item or achievement belongs to.
+type Village string
+
+const (
+	VillageUnknown     Village = "unknown"
+	VillageHome        Village = "home"
+	VillageBuilder     Village = "builderBase"
+	VillageClanCapital Village = "clanCapital"
+)
+
+var villageNames = map[Village]string{
+	VillageUnknown:     "Unknown",
+	VillageHome:        "Home Village",
+	VillageBuilder:     "Builder Base",
+	VillageClanCapital: "Clan Capital",
+}
+
+// IsValid reports whether v is a known Village, other than VillageUnknown.
+func (v Village) IsValid() bool {
+	_, ok := villageNames[v]
+	return ok && v != VillageUnknown
+}
+
+func (v Village) String() string {
+	return string(v)
+}
+
+// Format returns a human-readable name, or an empty string if v is not a Village.
+func (v Village) Format() string {
+	return villageNames[v]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to VillageUnknown.
+func (v *Village) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, v, VillageUnknown)
+}
+
+// PlayerHouseElementType is the type of a PlayerHouseElement.
+type PlayerHouseElementType string
+
+const (
+	PlayerHouseElementTypeUnknown PlayerHouseElementType = "unknown"
+	PlayerHouseElementTypeGround  PlayerHouseElementType = "ground"
+	PlayerHouseElementTypeRoof    PlayerHouseElementType = "roof"
+	PlayerHouseElementTypeFoot    PlayerHouseElementType = "foot"
+	PlayerHouseElementTypeDeco    PlayerHouseElementType = "deco"
+)
+
+var playerHouseElementTypeNames = map[PlayerHouseElementType]string{
+	PlayerHouseElementTypeUnknown: "Unknown",
+	PlayerHouseElementTypeGround:  "Ground",
+	PlayerHouseElementTypeRoof:    "Roof",
+	PlayerHouseElementTypeFoot:    "Foot",
+	PlayerHouseElementTypeDeco:    "Decoration",
+}
+
+// IsValid reports whether p is a known PlayerHouseElementType, other than PlayerHouseElementTypeUnknown.
+func (p PlayerHouseElementType) IsValid() bool {
+	_, ok := playerHouseElementTypeNames[p]
+	return ok && p != PlayerHouseElementTypeUnknown
+}
+
+func (p PlayerHouseElementType) String() string {
+	return string(p)
+}
+
+// Format returns a human-readable name, or an empty string if p is not a PlayerHouseElementType.
+func (p PlayerHouseElementType) Format() string {
+	return playerHouseElementTypeNames[p]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to PlayerHouseElementTypeUnknown.
+func (p *PlayerHouseElementType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, p, PlayerHouseElementTypeUnknown)
+}
+
+// BattleModifier is a modifier of the battles in a war.
+type BattleModifier string
+
+const (
+	BattleModifierUnknown  BattleModifier = "unknown"
+	BattleModifierNone     BattleModifier = "none"
+	BattleModifierHardMode BattleModifier = "hardMode"
+)
+
+var battleModifierNames = map[BattleModifier]string{
+	BattleModifierUnknown:  "Unknown",
+	BattleModifierNone:     "None",
+	BattleModifierHardMode: "Hard Mode",
+}
+
+// IsValid reports whether b is a known BattleModifier, other than BattleModifierUnknown.
+func (b BattleModifier) IsValid() bool {
+	_, ok := battleModifierNames[b]
+	return ok && b != BattleModifierUnknown
+}
+
+func (b BattleModifier) String() string {
+	return string(b)
+}
+
+// Format returns a human-readable name, or an empty string if b is not a BattleModifier.
+func (b BattleModifier) Format() string {
+	return battleModifierNames[b]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to BattleModifierUnknown.
+func (b *BattleModifier) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, b, BattleModifierUnknown)
+}
+
+// ClanWarState is the state of a ClanWar.
+type ClanWarState string
+
+const (
+	ClanWarStateUnknown       ClanWarState = "unknown"
+	ClanWarStateClanNotFound  ClanWarState = "clanNotFound"
+	ClanWarStateAccessDenied  ClanWarState = "accessDenied"
+	ClanWarStateNotInWar      ClanWarState = "notInWar"
+	ClanWarStateInMatchmaking ClanWarState = "inMatchmaking"
+	ClanWarStateEnterWar      ClanWarState = "enterWar"
+	ClanWarStateMatched       ClanWarState = "matched"
+	ClanWarStatePreparation   ClanWarState = "preparation"
+	ClanWarStateWar           ClanWarState = "war"
+	ClanWarStateInWar         ClanWarState = "inWar"
+	ClanWarStateWarEnded      ClanWarState = "warEnded"
+	// Deprecated: the API reports ended wars as ClanWarStateWarEnded.
+	ClanWarStateEnded ClanWarState = "ended"
+)
+
+var clanWarStateNames = map[ClanWarState]string{
+	ClanWarStateUnknown:       "Unknown",
+	ClanWarStateClanNotFound:  "Clan Not Found",
+	ClanWarStateAccessDenied:  "Access Denied",
+	ClanWarStateNotInWar:      "Not In War",
+	ClanWarStateInMatchmaking: "In Matchmaking",
+	ClanWarStateEnterWar:      "Enter War",
+	ClanWarStateMatched:       "Matched",
+	ClanWarStatePreparation:   "Preparation",
+	ClanWarStateWar:           "War",
+	ClanWarStateInWar:         "In War",
+	ClanWarStateWarEnded:      "War Ended",
+	ClanWarStateEnded:         "Ended",
+}
+
+// IsValid reports whether c is a known ClanWarState, other than ClanWarStateUnknown.
+func (c ClanWarState) IsValid() bool {
+	_, ok := clanWarStateNames[c]
+	return ok && c != ClanWarStateUnknown
+}
+
+func (c ClanWarState) String() string {
+	return string(c)
+}
+
+// Format returns a human-readable name, or an empty string if c is not a ClanWarState.
+func (c ClanWarState) Format() string {
+	return clanWarStateNames[c]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to ClanWarStateUnknown.
+func (c *ClanWarState) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ClanWarStateUnknown)
+}
+
+// ClanWarLeagueGroupState is the state of a ClanWarLeagueGroup.
+type ClanWarLeagueGroupState string
+
+const (
+	ClanWarLeagueGroupStateUnknown  ClanWarLeagueGroupState = "unknown"
+	ClanWarLeagueGroupStateNotFound ClanWarLeagueGroupState = "groupNotFound"
+	ClanWarLeagueGroupStateNotInWar ClanWarLeagueGroupState = "notInWar"
+	ClanWarLeagueGroupStatePrep     ClanWarLeagueGroupState = "preparation"
+	ClanWarLeagueGroupStateInWar    ClanWarLeagueGroupState = "inWar"
+	ClanWarLeagueGroupStateEnded    ClanWarLeagueGroupState = "ended"
+	// Deprecated: the API reports groups at war as ClanWarLeagueGroupStateInWar.
+	ClanWarLeagueGroupStateWar ClanWarLeagueGroupState = "war"
+)
+
+var clanWarLeagueGroupStateNames = map[ClanWarLeagueGroupState]string{
+	ClanWarLeagueGroupStateUnknown:  "Unknown",
+	ClanWarLeagueGroupStateNotFound: "Group Not Found",
+	ClanWarLeagueGroupStateNotInWar: "Not In War",
+	ClanWarLeagueGroupStatePrep:     "Preparation",
+	ClanWarLeagueGroupStateInWar:    "In War",
+	ClanWarLeagueGroupStateWar:      "War",
+	ClanWarLeagueGroupStateEnded:    "Ended",
+}
+
+// IsValid reports whether c is a known ClanWarLeagueGroupState, other than ClanWarLeagueGroupStateUnknown.
+func (c ClanWarLeagueGroupState) IsValid() bool {
+	_, ok := clanWarLeagueGroupStateNames[c]
+	return ok && c != ClanWarLeagueGroupStateUnknown
+}
+
+func (c ClanWarLeagueGroupState) String() string {
+	return string(c)
+}
+
+// Format returns a human-readable name, or an empty string if c is not a ClanWarLeagueGroupState.
+func (c ClanWarLeagueGroupState) Format() string {
+	return clanWarLeagueGroupStateNames[c]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to ClanWarLeagueGroupStateUnknown.
+func (c *ClanWarLeagueGroupState) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ClanWarLeagueGroupStateUnknown)
+}
+
+// ClanWarResult is the result of a war.
+type ClanWarResult string
+
+const (
+	ClanWarResultUnknown ClanWarResult = "unknown"
+	ClanWarResultWin     ClanWarResult = "win"
+	ClanWarResultLose    ClanWarResult = "lose"
+	ClanWarResultTie     ClanWarResult = "tie"
+)
+
+var clanWarResultNames = map[ClanWarResult]string{
+	ClanWarResultUnknown: "Unknown",
+	ClanWarResultWin:     "Win",
+	ClanWarResultLose:    "Loss",
+	ClanWarResultTie:     "Tie",
+}
+
+// IsValid reports whether c is a known ClanWarResult, other than ClanWarResultUnknown.
+func (c ClanWarResult) IsValid() bool {
+	_, ok := clanWarResultNames[c]
+	return ok && c != ClanWarResultUnknown
+}
+
+func (c ClanWarResult) String() string {
+	return string(c)
+}
+
+// Format returns a human-readable name, or an empty string if c is not a ClanWarResult.
+func (c ClanWarResult) Format() string {
+	return clanWarResultNames[c]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, mapping unknown values to ClanWarResultUnknown.
+func (c *ClanWarResult) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, ClanWarResultUnknown)
+}
+
+// unmarshalEnum unmarshals a JSON string into v, or sets v to unknown if the string is not a valid T.
+func unmarshalEnum[T interface {
+	~string
+	IsValid() bool
+}](data []byte, v *T, unknown T) error {
+	var s string
+	if err := sonic.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if *v = T(s); !(*v).IsValid() {
+		*v = unknown
+	}
+	return nil
+}
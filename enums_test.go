@@ -0,0 +1,29 @@
+package goclash
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestEnumUnmarshal(t *testing.T) {
+	var clan Clan
+	if err := sonic.Unmarshal([]byte(`{"type":"inviteOnly","warFrequency":"everyHour"}`), &clan); err != nil {
+		t.Fatal(err)
+	}
+	if clan.Type != ClanTypeInviteOnly || !clan.Type.IsValid() || clan.Type.Format() != "Invite Only" {
+		t.Fatalf("got type %q, want %q", clan.Type, ClanTypeInviteOnly)
+	}
+	if clan.WarFrequency != WarFrequencyUnrecognized || clan.WarFrequency.IsValid() {
+		t.Fatalf("got war frequency %q for an unknown value, want %q", clan.WarFrequency, WarFrequencyUnrecognized)
+	}
+	if err := sonic.Unmarshal([]byte(`{"warFrequency":"unknown"}`), &clan); err != nil {
+		t.Fatal(err)
+	}
+	if clan.WarFrequency != WarFrequencyUnknown || !clan.WarFrequency.IsValid() {
+		t.Fatalf("got war frequency %q, want the valid %q", clan.WarFrequency, WarFrequencyUnknown)
+	}
+	if ClanWarState("everyHour").Format() != "" || ClanWarResultLose.Format() != "Loss" {
+		t.Fatal("got unexpected formats")
+	}
+}
@@ -1,6 +1,16 @@
 package goclash
 
-// APIError is the error directly returned by the Clash of Clans API. Every error returned by Client is ClientError, which embeds *APIError.
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-resty/resty/v2"
+)
+
+// APIError is the error directly returned by the Clash of Clans API. Every error the API responds with is returned as *ClientError, which embeds *APIError.
 type APIError struct {
 	Reason  string `json:"reason"`
 	Message string `json:"message"`
@@ -12,14 +22,160 @@ const (
 	ReasonInvalidAuthorization = "accessDenied"
 	ReasonInvalidIP            = "accessDenied.invalidIp"
 	ReasonNotFound             = "notFound"
+	ReasonRequestThrottled     = "requestThrottled"
+	ReasonInMaintenance        = "inMaintenance"
+)
+
+// Errors a *ClientError can be matched against using errors.Is.
+var (
+	// ErrNotFound matches responses with http.StatusNotFound, e.g. for a tag that doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAccessDenied matches responses with http.StatusForbidden, including ErrInvalidIP and ErrPrivateWarLog.
+	ErrAccessDenied = errors.New("access denied")
+	// ErrInvalidIP matches responses to API keys that are not valid for the current IP address.
+	ErrInvalidIP = errors.New("API key is not valid for this IP address")
+	// ErrRateLimited matches responses with http.StatusTooManyRequests, returned once all retries (see WithMaxRetries) were throttled as well.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrMaintenance matches responses with http.StatusServiceUnavailable, which the API responds with during maintenance breaks.
+	ErrMaintenance = errors.New("API is in maintenance")
+	// ErrPrivateWarLog matches responses to war endpoints of clans whose war log is private.
+	ErrPrivateWarLog = errors.New("clan war log is private")
 )
 
-// ClientError is the error type returned by the client.
+// ClientError is the error type returned by the client when the API responds with an error. Use errors.Is to match it against ErrNotFound and the other errors above.
 type ClientError struct {
 	*APIError
-	Status int `json:"status"`
+	Status int    `json:"status"`
+	URL    string `json:"url"` // URL is the URL of the failed request.
 }
 
 func (e *ClientError) Error() string {
-	return e.Message
+	msg := fmt.Sprintf("request to %s failed with status %d", e.URL, e.Status)
+	if e.APIError == nil {
+		return msg
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is reports whether e matches target, which is one of the errors above.
+func (e *ClientError) Is(target error) bool {
+	var reason string
+	if e.APIError != nil {
+		reason = e.Reason
+	}
+
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound || reason == ReasonNotFound
+	case ErrAccessDenied:
+		return e.Status == http.StatusForbidden
+	case ErrInvalidIP:
+		return e.Status == http.StatusForbidden && reason == ReasonInvalidIP
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests || reason == ReasonRequestThrottled
+	case ErrMaintenance:
+		return e.Status == http.StatusServiceUnavailable || reason == ReasonInMaintenance
+	case ErrPrivateWarLog:
+		return e.isPrivateWarLog()
+	}
+	return false
+}
+
+// isPrivateWarLog reports whether the API denied access to a war endpoint because of a private war log.
+// The API responds with ReasonInvalidAuthorization in this case as well, so only the message tells it apart from an invalid API key.
+func (e *ClientError) isPrivateWarLog() bool {
+	if e.Status != http.StatusForbidden || e.APIError == nil || e.Reason != ReasonInvalidAuthorization {
+		return false
+	}
+	if strings.Contains(strings.ToLower(e.Message), "authorization") {
+		return false
+	}
+	return strings.HasSuffix(e.URL, "/warlog") || strings.HasSuffix(e.URL, "/currentwar") || strings.HasSuffix(e.URL, "/currentwar/leaguegroup")
+}
+
+// DevPortalOp is an operation on the developer portal, which the client uses to manage API keys.
+type DevPortalOp string
+
+const (
+	DevPortalLogin     DevPortalOp = "login"
+	DevPortalListKeys  DevPortalOp = "list keys"
+	DevPortalCreateKey DevPortalOp = "create key"
+	DevPortalRevokeKey DevPortalOp = "revoke key"
+)
+
+// ErrInvalidCredentials matches a *DevPortalError of a login with a wrong email or password.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// DevPortalError is returned when the developer portal responds with an error, e.g. when logging in or creating an API key fails.
+type DevPortalError struct {
+	Op     DevPortalOp
+	Email  string // Email is the email of the account the operation failed for.
+	Status int
+	Body   string // Body is the raw response body.
+}
+
+func (e *DevPortalError) Error() string {
+	msg := fmt.Sprintf("developer portal: %s failed for %s with status %d", e.Op, e.Email, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether e matches target, which is ErrInvalidCredentials or ErrRateLimited.
+func (e *DevPortalError) Is(target error) bool {
+	switch target {
+	case ErrInvalidCredentials:
+		return e.Op == DevPortalLogin && (e.Status == http.StatusForbidden || e.Status == http.StatusUnauthorized)
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// notSentError wraps an error that occurred before a request was sent, e.g. while waiting for the rate limit, so the API never received it.
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// notSent wraps err in a *notSentError if it occurred before the first attempt of a request was sent.
+func notSent(attempt int, err error) error {
+	if attempt > 0 {
+		return err
+	}
+	return &notSentError{err: err}
+}
+
+// wasNotSent reports whether err occurred before the request was sent to the API.
+func wasNotSent(err error) bool {
+	var notSentErr *notSentError
+	return errors.As(err, &notSentErr)
+}
+
+// newClientError creates the error for a response of the API with an error status.
+func newClientError(url string, res *resty.Response) *ClientError {
+	clientErr := &ClientError{Status: res.StatusCode(), URL: url, APIError: &APIError{}}
+	if err := sonic.Unmarshal(res.Body(), &clientErr.APIError); err != nil || clientErr.APIError == nil {
+		// e.g. an HTML error page of a proxy
+		clientErr.APIError = &APIError{Message: http.StatusText(res.StatusCode())}
+	}
+	return clientErr
+}
+
+func newDevPortalError(op DevPortalOp, account *APIAccount, res *resty.Response) *DevPortalError {
+	return &DevPortalError{Op: op, Email: account.Credentials.Email, Status: res.StatusCode(), Body: string(res.Body())}
 }
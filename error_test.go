@@ -0,0 +1,92 @@
+package goclash
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientErrorIs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/warlog"):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"reason":"accessDenied","message":"Access denied, clan war log is private."}`)
+		case strings.HasSuffix(r.URL.Path, "/currentwar"):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"reason":"accessDenied.invalidIp","message":"Invalid authorization: API key does not allow access from IP 1.2.3.4"}`)
+		case strings.HasPrefix(r.URL.Path, "/players/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"reason":"notFound"}`)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `<html>maintenance</html>`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	_, warLogErr := client.GetClanWarLog("#2PP", nil)
+	_, invalidIPErr := client.GetCurrentClanWar("#2PP")
+	_, notFoundErr := client.GetPlayer("#2PP")
+	_, maintenanceErr := client.GetClan("#2PP")
+	tests := []struct {
+		name    string
+		err     error
+		matches []error
+	}{
+		{"private war log", warLogErr, []error{ErrPrivateWarLog, ErrAccessDenied}},
+		{"invalid IP", invalidIPErr, []error{ErrInvalidIP, ErrAccessDenied}},
+		{"not found", notFoundErr, []error{ErrNotFound}},
+		{"maintenance", maintenanceErr, []error{ErrMaintenance}},
+	}
+	all := []error{ErrNotFound, ErrAccessDenied, ErrInvalidIP, ErrRateLimited, ErrMaintenance, ErrPrivateWarLog}
+	for _, tt := range tests {
+		var clientErr *ClientError
+		if !errors.As(tt.err, &clientErr) || clientErr.URL == "" || clientErr.Status == 0 {
+			t.Errorf("%s: got %v, want *ClientError with URL and status", tt.name, tt.err)
+			continue
+		}
+		for _, target := range all {
+			want := false
+			for _, match := range tt.matches {
+				want = want || match == target
+			}
+			if got := errors.Is(tt.err, target); got != want {
+				t.Errorf("%s: errors.Is(%v, %v) = %v, want %v", tt.name, tt.err, target, got, want)
+			}
+		}
+	}
+}
+
+func TestLoginError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ip":
+			fmt.Fprint(w, "1.2.3.4")
+		case string(DevLoginEndpoint):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"status":{"message":"invalidCredentials"}}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	_, err := New(Credentials{"email": "wrong"}, WithDevBaseURL(srv.URL), WithIPLookupURL(srv.URL+"/ip"))
+	var portalErr *DevPortalError
+	if !errors.As(err, &portalErr) || portalErr.Op != DevPortalLogin || portalErr.Email != "email" {
+		t.Fatalf("New returned %v, want *DevPortalError of login", err)
+	}
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("New returned %v, want ErrInvalidCredentials", err)
+	}
+}
@@ -0,0 +1,56 @@
+package goclash
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls with the same key into one, sharing its result with all callers.
+type flightGroup struct {
+	calls map[string]*flightCall
+	mu    sync.Mutex
+}
+
+// flightCall is a call in progress, or completed once done is closed.
+type flightCall struct {
+	done chan struct{}
+	body []byte
+	meta ResponseMeta
+	err  error
+}
+
+// do calls fn, unless a call with the same key is already in progress, in which case it waits for that call and returns its result.
+// Waiting is canceled together with ctx, without affecting the running call. shared reports whether the result is from another caller's call.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, ResponseMeta, error)) (body []byte, meta ResponseMeta, shared bool, err error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.body, call.meta, true, call.err
+		case <-ctx.Done():
+			return nil, ResponseMeta{}, true, ctx.Err()
+		}
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.body, call.meta, call.err = fn()
+	return call.body, call.meta, false, call.err
+}
+
+// isContextError reports whether err is caused by a canceled context or an exceeded deadline.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
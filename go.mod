@@ -1,12 +1,12 @@
 module github.com/aaantiii/goclash
 
-go 1.21.5
+go 1.23
 
 require (
 	github.com/bytedance/sonic v1.10.2
 	github.com/go-resty/resty/v2 v2.11.0
 	github.com/joho/godotenv v1.5.1
-	github.com/orcaman/concurrent-map/v2 v2.0.1
+	golang.org/x/net v0.20.0
 )
 
 require (
@@ -15,6 +15,5 @@ require (
 	github.com/klauspost/cpuid/v2 v2.2.6 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	golang.org/x/arch v0.7.0 // indirect
-	golang.org/x/net v0.20.0 // indirect
 	golang.org/x/sys v0.16.0 // indirect
 )
@@ -1,27 +1,36 @@
 package goclash
 
 import (
+	"context"
 	"net/http"
-
-	"github.com/bytedance/sonic"
+	"time"
 )
 
 type GoldPassSeason struct {
-	StartTime string
-	EndTime   string
+	ResponseMeta `json:"-"`
+
+	StartTime Time `json:"startTime"`
+	EndTime   Time `json:"endTime"`
+}
+
+// TimeRemaining returns the time left until the season ends, or 0 if it ended.
+func (s *GoldPassSeason) TimeRemaining() time.Duration {
+	return s.EndTime.Remaining()
 }
 
 // GetCurrentGoldPassSeason returns the current gold pass season.
 //
 // GET /goldpass/seasons/current
 func (h *Client) GetCurrentGoldPassSeason() (*GoldPassSeason, error) {
-	req := h.withAuth(h.newDefaultRequest())
-	data, err := h.do(http.MethodGet, GoldPassEndpoint.Build(), req, true)
+	return h.GetCurrentGoldPassSeasonCtx(context.Background())
+}
+
+// GetCurrentGoldPassSeasonCtx is like GetCurrentGoldPassSeason, but uses ctx for the request.
+func (h *Client) GetCurrentGoldPassSeasonCtx(ctx context.Context) (*GoldPassSeason, error) {
+	req := h.newDefaultRequest()
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(GoldPassEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
-
-	var season *GoldPassSeason
-	err = sonic.Unmarshal(data, &season)
-	return season, err
+	return decode[GoldPassSeason](data, meta)
 }
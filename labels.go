@@ -1,9 +1,9 @@
 package goclash
 
 import (
+	"context"
+	"iter"
 	"net/http"
-
-	"github.com/bytedance/sonic"
 )
 
 type LabelsData struct {
@@ -19,26 +19,44 @@ type Label struct {
 
 // GetPlayerLabels returns a paginated list of player labels. Pass params=nil to get all labels.
 func (h *Client) GetPlayerLabels(params *PagingParams) (*PaginatedResponse[Label], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LabelsEndpoint.Build("players"), req, true)
+	return h.GetPlayerLabelsCtx(context.Background(), params)
+}
+
+// GetPlayerLabelsCtx is like GetPlayerLabels, but uses ctx for the request.
+func (h *Client) GetPlayerLabelsCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[Label], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LabelsEndpoint, "players"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[Label]](data, meta)
+}
 
-	var labels *PaginatedResponse[Label]
-	err = sonic.Unmarshal(data, &labels)
-	return labels, err
+// IterPlayerLabels is like GetPlayerLabels, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterPlayerLabels(ctx context.Context, params *PagingParams) iter.Seq2[Label, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[Label], error) {
+		return h.GetPlayerLabelsCtx(ctx, params)
+	}, params)
 }
 
 // GetClanLabels returns a paginated list of clan labels. Pass params=nil to get all labels.
 func (h *Client) GetClanLabels(params *PagingParams) (*PaginatedResponse[Label], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LabelsEndpoint.Build("clans"), req, true)
+	return h.GetClanLabelsCtx(context.Background(), params)
+}
+
+// GetClanLabelsCtx is like GetClanLabels, but uses ctx for the request.
+func (h *Client) GetClanLabelsCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[Label], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LabelsEndpoint, "clans"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[Label]](data, meta)
+}
 
-	var labels *PaginatedResponse[Label]
-	err = sonic.Unmarshal(data, &labels)
-	return labels, err
+// IterClanLabels is like GetClanLabels, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanLabels(ctx context.Context, params *PagingParams) iter.Seq2[Label, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[Label], error) {
+		return h.GetClanLabelsCtx(ctx, params)
+	}, params)
 }
@@ -1,10 +1,10 @@
 package goclash
 
 import (
+	"context"
+	"iter"
 	"net/http"
 	"strconv"
-
-	"github.com/bytedance/sonic"
 )
 
 type WarLeague struct {
@@ -62,6 +62,12 @@ const (
 	LeagueLegend
 )
 
+// WarLeagueResponse is the WarLeague returned by GetWarLeague, together with the ResponseMeta of the response.
+type WarLeagueResponse struct {
+	ResponseMeta `json:"-"`
+	WarLeague
+}
+
 type LeagueData struct {
 	Paging  Paging   `json:"paging,omitempty"`
 	Leagues []League `json:"items,omitempty"`
@@ -78,6 +84,18 @@ type League struct {
 	ID       int       `json:"id,omitempty"`
 }
 
+// CapitalLeagueResponse is the CapitalLeague returned by GetCapitalLeague, together with the ResponseMeta of the response.
+type CapitalLeagueResponse struct {
+	ResponseMeta `json:"-"`
+	CapitalLeague
+}
+
+// LeagueResponse is the League returned by GetLeague, together with the ResponseMeta of the response.
+type LeagueResponse struct {
+	ResponseMeta `json:"-"`
+	League
+}
+
 // PlayerRankingList contains information about a player's ranking.
 type PlayerRankingList struct {
 	League       League            `json:"league"`
@@ -106,144 +124,206 @@ type LeagueSeason struct {
 //
 // GET /capitalleagues
 func (h *Client) GetCapitalLeagues(params *PagingParams) (*PaginatedResponse[CapitalLeague], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, CapitalLeaguesEndpoint.Build(), req, true)
+	return h.GetCapitalLeaguesCtx(context.Background(), params)
+}
+
+// GetCapitalLeaguesCtx is like GetCapitalLeagues, but uses ctx for the request.
+func (h *Client) GetCapitalLeaguesCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[CapitalLeague], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(CapitalLeaguesEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[CapitalLeague]](data, meta)
+}
 
-	var leagues *PaginatedResponse[CapitalLeague]
-	err = sonic.Unmarshal(data, &leagues)
-	return leagues, err
+// IterCapitalLeagues is like GetCapitalLeagues, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterCapitalLeagues(ctx context.Context, params *PagingParams) iter.Seq2[CapitalLeague, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[CapitalLeague], error) {
+		return h.GetCapitalLeaguesCtx(ctx, params)
+	}, params)
 }
 
 // GetLeagues returns a paginated list of leagues. Pass params=nil to get all leagues.
 //
 // GET /leagues
 func (h *Client) GetLeagues(params *PagingParams) (*PaginatedResponse[League], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LeaguesEndpoint.Build(), req, true)
+	return h.GetLeaguesCtx(context.Background(), params)
+}
+
+// GetLeaguesCtx is like GetLeagues, but uses ctx for the request.
+func (h *Client) GetLeaguesCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[League], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LeaguesEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[League]](data, meta)
+}
 
-	var leagues *PaginatedResponse[League]
-	err = sonic.Unmarshal(data, &leagues)
-	return leagues, err
+// IterLeagues is like GetLeagues, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterLeagues(ctx context.Context, params *PagingParams) iter.Seq2[League, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[League], error) {
+		return h.GetLeaguesCtx(ctx, params)
+	}, params)
 }
 
 // GetLegendLeagueRanking returns a paginated list of players in the provided legend league season.
 //
 // GET /leagues/{leagueId}/seasons/{seasonId}
 func (h *Client) GetLegendLeagueRanking(leagueID, seasonID string, params *PagingParams) (*PaginatedResponse[PlayerRankingList], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LeaguesEndpoint.Build(leagueID, "seasons", seasonID), req, true)
+	return h.GetLegendLeagueRankingCtx(context.Background(), leagueID, seasonID, params)
+}
+
+// GetLegendLeagueRankingCtx is like GetLegendLeagueRanking, but uses ctx for the request.
+func (h *Client) GetLegendLeagueRankingCtx(ctx context.Context, leagueID, seasonID string, params *PagingParams) (*PaginatedResponse[PlayerRankingList], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LeaguesEndpoint, leagueID, "seasons", seasonID), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[PlayerRankingList]](data, meta)
+}
 
-	var rankings *PaginatedResponse[PlayerRankingList]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterLegendLeagueRanking is like GetLegendLeagueRanking, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterLegendLeagueRanking(ctx context.Context, leagueID, seasonID string, params *PagingParams) iter.Seq2[PlayerRankingList, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[PlayerRankingList], error) {
+		return h.GetLegendLeagueRankingCtx(ctx, leagueID, seasonID, params)
+	}, params)
 }
 
 // GetCapitalLeague returns information about a single capital league.
 //
 // GET /capitalleagues/{leagueId}
-func (h *Client) GetCapitalLeague(id string) (*CapitalLeague, error) {
-	data, err := h.do(http.MethodGet, CapitalLeaguesEndpoint.Build(id), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetCapitalLeague(id string) (*CapitalLeagueResponse, error) {
+	return h.GetCapitalLeagueCtx(context.Background(), id)
+}
+
+// GetCapitalLeagueCtx is like GetCapitalLeague, but uses ctx for the request.
+func (h *Client) GetCapitalLeagueCtx(ctx context.Context, id string) (*CapitalLeagueResponse, error) {
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(CapitalLeaguesEndpoint, id), h.newDefaultRequest(), true)
 	if err != nil {
 		return nil, err
 	}
-
-	var league *CapitalLeague
-	err = sonic.Unmarshal(data, &league)
-	return league, err
+	return decode[CapitalLeagueResponse](data, meta)
 }
 
 // GetBuilderBaseLeague returns information about a single builder base league.
 //
 // GET /builderbaseleagues/{leagueId}
-func (h *Client) GetBuilderBaseLeague(id string) (*BuilderBaseLeague, error) {
-	data, err := h.do(http.MethodGet, BuilderBaseLeaguesEndpoint.Build(id), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetBuilderBaseLeague(id string) (*BuilderBaseLeagueResponse, error) {
+	return h.GetBuilderBaseLeagueCtx(context.Background(), id)
+}
+
+// GetBuilderBaseLeagueCtx is like GetBuilderBaseLeague, but uses ctx for the request.
+func (h *Client) GetBuilderBaseLeagueCtx(ctx context.Context, id string) (*BuilderBaseLeagueResponse, error) {
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(BuilderBaseLeaguesEndpoint, id), h.newDefaultRequest(), true)
 	if err != nil {
 		return nil, err
 	}
-
-	var league *BuilderBaseLeague
-	err = sonic.Unmarshal(data, &league)
-	return league, err
+	return decode[BuilderBaseLeagueResponse](data, meta)
 }
 
 // GetBuilderBaseLeagues returns a list of builder base leagues. Pass params=nil to get all leagues.
 //
 // GET /builderbaseleagues
 func (h *Client) GetBuilderBaseLeagues(params *PagingParams) (*PaginatedResponse[BuilderBaseLeague], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, BuilderBaseLeaguesEndpoint.Build(), req, true)
+	return h.GetBuilderBaseLeaguesCtx(context.Background(), params)
+}
+
+// GetBuilderBaseLeaguesCtx is like GetBuilderBaseLeagues, but uses ctx for the request.
+func (h *Client) GetBuilderBaseLeaguesCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[BuilderBaseLeague], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(BuilderBaseLeaguesEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[BuilderBaseLeague]](data, meta)
+}
 
-	var leagues *PaginatedResponse[BuilderBaseLeague]
-	err = sonic.Unmarshal(data, &leagues)
-	return leagues, err
+// IterBuilderBaseLeagues is like GetBuilderBaseLeagues, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterBuilderBaseLeagues(ctx context.Context, params *PagingParams) iter.Seq2[BuilderBaseLeague, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[BuilderBaseLeague], error) {
+		return h.GetBuilderBaseLeaguesCtx(ctx, params)
+	}, params)
 }
 
 // GetLeague returns information about a single league.
 //
 // GET /leagues/{leagueId}
-func (h *Client) GetLeague(id string) (*League, error) {
-	data, err := h.do(http.MethodGet, LeaguesEndpoint.Build(id), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetLeague(id string) (*LeagueResponse, error) {
+	return h.GetLeagueCtx(context.Background(), id)
+}
+
+// GetLeagueCtx is like GetLeague, but uses ctx for the request.
+func (h *Client) GetLeagueCtx(ctx context.Context, id string) (*LeagueResponse, error) {
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LeaguesEndpoint, id), h.newDefaultRequest(), true)
 	if err != nil {
 		return nil, err
 	}
-
-	var league *League
-	err = sonic.Unmarshal(data, &league)
-	return league, err
+	return decode[LeagueResponse](data, meta)
 }
 
 // GetLeagueSeasons returns a list of league seasons. Pass params=nil to get all seasons.
 //
 // GET /leagues/{leagueId}/seasons
 func (h *Client) GetLeagueSeasons(id int, params *PagingParams) (*PaginatedResponse[LeagueSeason], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LeaguesEndpoint.Build(strconv.Itoa(id), "seasons"), req, true)
+	return h.GetLeagueSeasonsCtx(context.Background(), id, params)
+}
+
+// GetLeagueSeasonsCtx is like GetLeagueSeasons, but uses ctx for the request.
+func (h *Client) GetLeagueSeasonsCtx(ctx context.Context, id int, params *PagingParams) (*PaginatedResponse[LeagueSeason], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LeaguesEndpoint, strconv.Itoa(id), "seasons"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[LeagueSeason]](data, meta)
+}
 
-	var seasons *PaginatedResponse[LeagueSeason]
-	err = sonic.Unmarshal(data, &seasons)
-	return seasons, err
+// IterLeagueSeasons is like GetLeagueSeasons, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterLeagueSeasons(ctx context.Context, id int, params *PagingParams) iter.Seq2[LeagueSeason, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[LeagueSeason], error) {
+		return h.GetLeagueSeasonsCtx(ctx, id, params)
+	}, params)
 }
 
 // GetWarLeague returns information about a single war league.
 //
 // GET /warleagues/{leagueId}
-func (h *Client) GetWarLeague(id string) (*WarLeague, error) {
-	data, err := h.do(http.MethodGet, WarLeaguesEndpoint.Build(id), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetWarLeague(id string) (*WarLeagueResponse, error) {
+	return h.GetWarLeagueCtx(context.Background(), id)
+}
+
+// GetWarLeagueCtx is like GetWarLeague, but uses ctx for the request.
+func (h *Client) GetWarLeagueCtx(ctx context.Context, id string) (*WarLeagueResponse, error) {
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(WarLeaguesEndpoint, id), h.newDefaultRequest(), true)
 	if err != nil {
 		return nil, err
 	}
-
-	var league *WarLeague
-	err = sonic.Unmarshal(data, &league)
-	return league, err
+	return decode[WarLeagueResponse](data, meta)
 }
 
-// GetWarLeagues returns a list of war leagues. Pass params=nil to get all leagues.
+// GetWarLeagues returns a paginated list of war leagues. Pass params=nil to get all leagues.
 //
 // GET /warleagues
-func (h *Client) GetWarLeagues(params *PagingParams) ([]*WarLeague, error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, WarLeaguesEndpoint.Build(), req, true)
+func (h *Client) GetWarLeagues(params *PagingParams) (*PaginatedResponse[WarLeague], error) {
+	return h.GetWarLeaguesCtx(context.Background(), params)
+}
+
+// GetWarLeaguesCtx is like GetWarLeagues, but uses ctx for the request.
+func (h *Client) GetWarLeaguesCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[WarLeague], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(WarLeaguesEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[WarLeague]](data, meta)
+}
 
-	var leagues []*WarLeague
-	err = sonic.Unmarshal(data, &leagues)
-	return leagues, err
+// IterWarLeagues is like GetWarLeagues, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterWarLeagues(ctx context.Context, params *PagingParams) iter.Seq2[WarLeague, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[WarLeague], error) {
+		return h.GetWarLeaguesCtx(ctx, params)
+	}, params)
 }
@@ -0,0 +1,265 @@
+package goclash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLinkMaxAttempts = 5
+	defaultLinkWindow      = time.Hour
+)
+
+// LinkerOptions configures a Linker. A nil *LinkerOptions uses the defaults.
+type LinkerOptions struct {
+	// MaxAttempts is how many tokens may be checked for a single tag within Window, which defaults to 5.
+	MaxAttempts int
+	// Window is the period attempts are counted in, which defaults to 1 hour.
+	Window time.Duration
+}
+
+func (o *LinkerOptions) withDefaults() LinkerOptions {
+	var opts LinkerOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultLinkMaxAttempts
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultLinkWindow
+	}
+	return opts
+}
+
+// AccountLink records that a user proved to own a player account, using the player's API token.
+type AccountLink struct {
+	UserID   string
+	Tag      Tag
+	Player   *Player
+	LinkedAt time.Time
+}
+
+// Linker links player accounts to users of your application, e.g. Discord users, after verifying the player's API token.
+// It limits how many tokens may be checked for a single tag, so that tokens can't be brute forced. A Linker is safe for concurrent use.
+//
+// Links are only kept in memory. Persist the AccountLink returned by Link to keep them across restarts, and restore them using Restore.
+type Linker struct {
+	client   *Client
+	opts     LinkerOptions
+	attempts map[Tag][]time.Time // attempts holds the times tokens were checked for a tag within the window, oldest first
+	swept    time.Time           // swept is when tags without attempts in the window were last removed from attempts
+	links    map[Tag]AccountLink
+	mu       sync.Mutex
+}
+
+// NewLinker returns a Linker verifying tokens using the client.
+func (h *Client) NewLinker(opts *LinkerOptions) *Linker {
+	return &Linker{
+		client:   h,
+		opts:     opts.withDefaults(),
+		attempts: make(map[Tag][]time.Time),
+		swept:    time.Now(),
+		links:    make(map[Tag]AccountLink),
+	}
+}
+
+// Link verifies that token is the API token of the player with the tag, and links the player to userID. If the player was linked to another user before, the link is replaced, since only the current owner knows the token.
+//
+// It fails with ErrInvalidTag, *TooManyAttemptsError, *InvalidTokenError, *PlayerNotFoundError or *TransientLinkError. Other errors, like an invalid API key, are returned as they are.
+func (l *Linker) Link(ctx context.Context, userID string, tag Tag, token string) (*AccountLink, error) {
+	tag, err := ParseTag(string(tag))
+	if err != nil {
+		return nil, err
+	}
+	attempt, err := l.reserveAttempt(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := l.client.VerifyPlayerCtx(ctx, tag, token)
+	if err != nil {
+		// a request that failed during the round-trip may still have reached the API, so only requests that were never sent are refunded
+		if wasNotSent(err) {
+			l.refundAttempt(tag, attempt)
+		}
+		return nil, linkError(tag, err)
+	}
+	if !verification.IsOk() {
+		return nil, &InvalidTokenError{Tag: tag}
+	}
+	l.resetAttempts(tag)
+
+	player, err := l.client.GetPlayerCtx(ctx, tag)
+	if err != nil {
+		return nil, linkError(tag, err)
+	}
+
+	link := AccountLink{UserID: userID, Tag: tag, Player: player, LinkedAt: time.Now()}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.links[tag] = link
+	return &link, nil
+}
+
+// Restore adds links that were persisted before, e.g. when your application restarts.
+func (l *Linker) Restore(links ...AccountLink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, link := range links {
+		link.Tag = normalizeTag(link.Tag)
+		l.links[link.Tag] = link
+	}
+}
+
+// Unlink removes the link of the player with the tag, and reports whether it was linked.
+func (l *Linker) Unlink(tag Tag) bool {
+	tag = normalizeTag(tag)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.links[tag]
+	delete(l.links, tag)
+	return ok
+}
+
+// LinkOf returns the link of the player with the tag.
+func (l *Linker) LinkOf(tag Tag) (AccountLink, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	link, ok := l.links[normalizeTag(tag)]
+	return link, ok
+}
+
+// LinksOf returns the links of all players linked to userID.
+func (l *Linker) LinksOf(userID string) []AccountLink {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var links []AccountLink
+	for _, link := range l.links {
+		if link.UserID == userID {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// reserveAttempt counts an attempt for the tag and returns its time, or returns a *TooManyAttemptsError if the tag has no attempts left.
+// Once per window, tags without attempts in the window are removed, so that attempts doesn't grow with every tag ever tried.
+func (l *Linker) reserveAttempt(tag Tag) (time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.swept) >= l.opts.Window {
+		for t := range l.attempts {
+			l.expireAttempts(t, now)
+		}
+		l.swept = now
+	}
+
+	attempts := l.expireAttempts(tag, now)
+	if len(attempts) >= l.opts.MaxAttempts {
+		return time.Time{}, &TooManyAttemptsError{Tag: tag, RetryAfter: attempts[0].Add(l.opts.Window).Sub(now)}
+	}
+	l.attempts[tag] = append(attempts, now)
+	return now, nil
+}
+
+// expireAttempts removes the attempts of the tag that are outside the window, deleting the tag once none are left, and returns the remaining ones.
+// The caller must hold l.mu.
+func (l *Linker) expireAttempts(tag Tag, now time.Time) []time.Time {
+	attempts := l.attempts[tag]
+	for len(attempts) > 0 && now.Sub(attempts[0]) >= l.opts.Window {
+		attempts = attempts[1:]
+	}
+	if len(attempts) == 0 {
+		delete(l.attempts, tag)
+		return nil
+	}
+	l.attempts[tag] = attempts
+	return attempts
+}
+
+// refundAttempt removes the attempt of the tag reserved at the given time again, because the token could not be checked.
+func (l *Linker) refundAttempt(tag Tag, attempt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	attempts := l.attempts[tag]
+	if i := slices.IndexFunc(attempts, attempt.Equal); i >= 0 {
+		attempts = slices.Delete(attempts, i, i+1)
+	}
+	if len(attempts) == 0 {
+		delete(l.attempts, tag)
+		return
+	}
+	l.attempts[tag] = attempts
+}
+
+// resetAttempts forgets the attempts of the tag, once its token was verified.
+func (l *Linker) resetAttempts(tag Tag) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, tag)
+}
+
+// linkError classifies an error returned by the API while linking the player with the tag.
+func linkError(tag Tag, err error) error {
+	var clientErr *ClientError
+	switch {
+	case !errors.As(err, &clientErr):
+		return &TransientLinkError{Tag: tag, Err: err}
+	case errors.Is(err, ErrNotFound):
+		return &PlayerNotFoundError{Tag: tag}
+	case clientErr.Status >= http.StatusInternalServerError || isRetryableStatus(clientErr.Status):
+		return &TransientLinkError{Tag: tag, Err: err}
+	}
+	return err
+}
+
+// InvalidTokenError is returned by Linker.Link when the token is not the API token of the player.
+type InvalidTokenError struct {
+	Tag Tag
+}
+
+func (e *InvalidTokenError) Error() string {
+	return "invalid API token for player " + e.Tag.String()
+}
+
+// PlayerNotFoundError is returned by Linker.Link when no player with the tag exists.
+type PlayerNotFoundError struct {
+	Tag Tag
+}
+
+func (e *PlayerNotFoundError) Error() string {
+	return "player " + e.Tag.String() + " not found"
+}
+
+// TransientLinkError is returned by Linker.Link when the token could not be checked for a reason that may go away, like a timeout or maintenance, and may be retried.
+// The attempt is only not counted if the request was never sent, e.g. because ctx was done before. Timeouts during the request count, since the API may have checked the token.
+type TransientLinkError struct {
+	Tag Tag
+	Err error
+}
+
+func (e *TransientLinkError) Error() string {
+	return "linking player " + e.Tag.String() + ": " + e.Err.Error()
+}
+
+func (e *TransientLinkError) Unwrap() error {
+	return e.Err
+}
+
+// TooManyAttemptsError is returned by Linker.Link when too many tokens were checked for the tag recently.
+type TooManyAttemptsError struct {
+	Tag        Tag
+	RetryAfter time.Duration // RetryAfter is how long until the next attempt is allowed.
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many attempts to link player %s, retry after %v", e.Tag, e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,160 @@
+package goclash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/players/"), "/verifytoken")
+		switch {
+		case tag == "#2QQ":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"reason":"notFound"}`)
+		case tag == "#2GG":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"reason":"inMaintenance"}`)
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			status := PlayerVerificationStatusInvalid
+			if strings.Contains(string(body), `"secret"`) {
+				status = PlayerVerificationStatusOk
+			}
+			fmt.Fprintf(w, `{"tag":%q,"status":%q}`, tag, status)
+		default:
+			fmt.Fprintf(w, `{"tag":%q,"name":"test"}`, tag)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	linker := client.NewLinker(&LinkerOptions{MaxAttempts: 2})
+	ctx := context.Background()
+
+	var invalidToken *InvalidTokenError
+	if _, err = linker.Link(ctx, "user", "#2PP", "guess"); !errors.As(err, &invalidToken) {
+		t.Fatalf("Link with wrong token returned %v, want *InvalidTokenError", err)
+	}
+	link, err := linker.Link(ctx, "user", "2pp", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Tag != "#2PP" || link.Player.Name != "test" {
+		t.Fatalf("Link returned %+v", link)
+	}
+	if got, ok := linker.LinkOf("#2PP"); !ok || got.UserID != "user" {
+		t.Fatalf("LinkOf(#2PP) = %+v, %v", got, ok)
+	}
+	if links := linker.LinksOf("user"); len(links) != 1 {
+		t.Fatalf("LinksOf(user) returned %d links, want 1", len(links))
+	}
+
+	// a successful link resets the attempts, so two more guesses are allowed
+	for range 2 {
+		if _, err = linker.Link(ctx, "other", "#2PP", "guess"); !errors.As(err, &invalidToken) {
+			t.Fatalf("Link with wrong token returned %v, want *InvalidTokenError", err)
+		}
+	}
+	var tooMany *TooManyAttemptsError
+	if _, err = linker.Link(ctx, "other", "#2PP", "secret"); !errors.As(err, &tooMany) || tooMany.RetryAfter <= 0 {
+		t.Fatalf("third attempt returned %v, want *TooManyAttemptsError", err)
+	}
+
+	var notFound *PlayerNotFoundError
+	if _, err = linker.Link(ctx, "user", "#2QQ", "secret"); !errors.As(err, &notFound) {
+		t.Fatalf("Link for missing player returned %v, want *PlayerNotFoundError", err)
+	}
+	// failures after the API responded count as attempts, but requests that never reached it don't
+	var transient *TransientLinkError
+	for range 2 {
+		if _, err = linker.Link(ctx, "user", "#2GG", "secret"); !errors.As(err, &transient) {
+			t.Fatalf("Link during maintenance returned %v, want *TransientLinkError", err)
+		}
+	}
+	if _, err = linker.Link(ctx, "user", "#2GG", "secret"); !errors.As(err, &tooMany) {
+		t.Fatalf("third attempt during maintenance returned %v, want *TooManyAttemptsError", err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for range 3 {
+		if _, err = linker.Link(canceled, "user", "#2YY", "secret"); !errors.As(err, &transient) || !errors.Is(err, context.Canceled) {
+			t.Fatalf("Link with canceled context returned %v, want *TransientLinkError", err)
+		}
+	}
+	if _, err = linker.Link(ctx, "user", "#2X", "secret"); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("Link with invalid tag returned %v, want ErrInvalidTag", err)
+	}
+
+	if !linker.Unlink("#2PP") || linker.Unlink("#2PP") {
+		t.Fatal("Unlink did not remove the link exactly once")
+	}
+}
+
+func TestLinkerForgetsExpiredAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"invalid"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	linker := client.NewLinker(&LinkerOptions{MaxAttempts: 1, Window: 20 * time.Millisecond})
+
+	var invalidToken *InvalidTokenError
+	if _, err = linker.Link(context.Background(), "user", "#2PP", "guess"); !errors.As(err, &invalidToken) {
+		t.Fatalf("Link returned %v, want *InvalidTokenError", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err = linker.Link(context.Background(), "user", "#2QQ", "guess"); !errors.As(err, &invalidToken) {
+		t.Fatalf("Link returned %v, want *InvalidTokenError", err)
+	}
+
+	linker.mu.Lock()
+	defer linker.mu.Unlock()
+	if _, ok := linker.attempts["#2PP"]; ok || len(linker.attempts) != 1 {
+		t.Fatalf("got attempts for %d tags, want only the attempt for #2QQ", len(linker.attempts))
+	}
+}
+
+func TestLinkerCountsTimeouts(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL), WithMaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	linker := client.NewLinker(&LinkerOptions{MaxAttempts: 1})
+
+	// the API may have checked the token before the request timed out, so the attempt counts
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var transient *TransientLinkError
+	if _, err = linker.Link(ctx, "user", "#2PP", "secret"); !errors.As(err, &transient) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Link timing out returned %v, want *TransientLinkError", err)
+	}
+	var tooMany *TooManyAttemptsError
+	if _, err = linker.Link(context.Background(), "user", "#2PP", "secret"); !errors.As(err, &tooMany) {
+		t.Fatalf("Link after a timeout returned %v, want *TooManyAttemptsError", err)
+	}
+}
@@ -1,10 +1,10 @@
 package goclash
 
 import (
+	"context"
+	"iter"
 	"net/http"
 	"strconv"
-
-	"github.com/bytedance/sonic"
 )
 
 type Location struct {
@@ -15,6 +15,12 @@ type Location struct {
 	CountryCode   string `json:"countryCode"`
 }
 
+// LocationResponse is the Location returned by GetLocation, together with the ResponseMeta of the response.
+type LocationResponse struct {
+	ResponseMeta `json:"-"`
+	Location
+}
+
 type ClanRanking struct {
 	ClanLevel    int       `json:"clanLevel"`
 	ClanPoints   int       `json:"clanPoints"`
@@ -52,115 +58,187 @@ type PlayerBuilderBaseRanking struct {
 }
 
 type ClanBuilderBaseRanking struct {
-	ClanPoints            int `json:"clanPoints"`
-	ClanBuilderBasePoints int `json:"clanBuilderBasePoints"`
+	ClanLevel             int       `json:"clanLevel"`
+	ClanPoints            int       `json:"clanPoints"`
+	ClanBuilderBasePoints int       `json:"clanBuilderBasePoints"`
+	Location              Location  `json:"location"`
+	Members               int       `json:"members"`
+	Tag                   string    `json:"tag"`
+	Name                  string    `json:"name"`
+	Rank                  int       `json:"rank"`
+	PreviousRank          int       `json:"previousRank"`
+	BadgeURLs             ImageURLs `json:"badgeUrls"`
 }
 
 type ClanCapitalRanking struct {
-	ClanPoints        int `json:"clanPoints"`
-	ClanCapitalPoints int `json:"clanCapitalPoints"`
+	ClanLevel         int       `json:"clanLevel"`
+	ClanPoints        int       `json:"clanPoints"`
+	ClanCapitalPoints int       `json:"clanCapitalPoints"`
+	Location          Location  `json:"location"`
+	Members           int       `json:"members"`
+	Tag               string    `json:"tag"`
+	Name              string    `json:"name"`
+	Rank              int       `json:"rank"`
+	PreviousRank      int       `json:"previousRank"`
+	BadgeURLs         ImageURLs `json:"badgeUrls"`
 }
 
 // GetClanRankings returns a paginated list of clan rankings for a specific location.
 //
 // GET /locations/{locationId}/rankings/clans
 func (h *Client) GetClanRankings(locationID int, params *PagingParams) (*PaginatedResponse[ClanRanking], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID), "rankings/clans"), req, true)
+	return h.GetClanRankingsCtx(context.Background(), locationID, params)
+}
+
+// GetClanRankingsCtx is like GetClanRankings, but uses ctx for the request.
+func (h *Client) GetClanRankingsCtx(ctx context.Context, locationID int, params *PagingParams) (*PaginatedResponse[ClanRanking], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID), "rankings/clans"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[ClanRanking]](data, meta)
+}
 
-	var rankings *PaginatedResponse[ClanRanking]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterClanRankings is like GetClanRankings, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanRankings(ctx context.Context, locationID int, params *PagingParams) iter.Seq2[ClanRanking, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanRanking], error) {
+		return h.GetClanRankingsCtx(ctx, locationID, params)
+	}, params)
 }
 
 // GetPlayerRankings returns a paginated list of player rankings for a specific location.
 //
 // GET /locations/{locationId}/rankings/players
 func (h *Client) GetPlayerRankings(locationID int, params *PagingParams) (*PaginatedResponse[PlayerRanking], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID), "rankings/players"), req, true)
+	return h.GetPlayerRankingsCtx(context.Background(), locationID, params)
+}
+
+// GetPlayerRankingsCtx is like GetPlayerRankings, but uses ctx for the request.
+func (h *Client) GetPlayerRankingsCtx(ctx context.Context, locationID int, params *PagingParams) (*PaginatedResponse[PlayerRanking], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID), "rankings/players"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[PlayerRanking]](data, meta)
+}
 
-	var rankings *PaginatedResponse[PlayerRanking]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterPlayerRankings is like GetPlayerRankings, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterPlayerRankings(ctx context.Context, locationID int, params *PagingParams) iter.Seq2[PlayerRanking, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[PlayerRanking], error) {
+		return h.GetPlayerRankingsCtx(ctx, locationID, params)
+	}, params)
 }
 
 // GetPlayerBuilderBaseRankings returns a paginated list of player builder base rankings for a specific location.
 //
 // GET /locations/{locationId}/rankings/players-builder-base
 func (h *Client) GetPlayerBuilderBaseRankings(locationID int, params *PagingParams) (*PaginatedResponse[PlayerBuilderBaseRanking], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID), "rankings/players-builder-base"), req, true)
+	return h.GetPlayerBuilderBaseRankingsCtx(context.Background(), locationID, params)
+}
+
+// GetPlayerBuilderBaseRankingsCtx is like GetPlayerBuilderBaseRankings, but uses ctx for the request.
+func (h *Client) GetPlayerBuilderBaseRankingsCtx(ctx context.Context, locationID int, params *PagingParams) (*PaginatedResponse[PlayerBuilderBaseRanking], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID), "rankings/players-builder-base"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[PlayerBuilderBaseRanking]](data, meta)
+}
 
-	var rankings *PaginatedResponse[PlayerBuilderBaseRanking]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterPlayerBuilderBaseRankings is like GetPlayerBuilderBaseRankings, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterPlayerBuilderBaseRankings(ctx context.Context, locationID int, params *PagingParams) iter.Seq2[PlayerBuilderBaseRanking, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[PlayerBuilderBaseRanking], error) {
+		return h.GetPlayerBuilderBaseRankingsCtx(ctx, locationID, params)
+	}, params)
 }
 
 // GetClanBuilderBaseRankings returns a paginated list of clan builder base rankings for a specific location.
 //
 // GET /locations/{locationId}/rankings/clans-builder-base
 func (h *Client) GetClanBuilderBaseRankings(locationID int, params *PagingParams) (*PaginatedResponse[ClanBuilderBaseRanking], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID), "rankings/clans-builder-base"), req, true)
+	return h.GetClanBuilderBaseRankingsCtx(context.Background(), locationID, params)
+}
+
+// GetClanBuilderBaseRankingsCtx is like GetClanBuilderBaseRankings, but uses ctx for the request.
+func (h *Client) GetClanBuilderBaseRankingsCtx(ctx context.Context, locationID int, params *PagingParams) (*PaginatedResponse[ClanBuilderBaseRanking], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID), "rankings/clans-builder-base"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[ClanBuilderBaseRanking]](data, meta)
+}
 
-	var rankings *PaginatedResponse[ClanBuilderBaseRanking]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterClanBuilderBaseRankings is like GetClanBuilderBaseRankings, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanBuilderBaseRankings(ctx context.Context, locationID int, params *PagingParams) iter.Seq2[ClanBuilderBaseRanking, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanBuilderBaseRanking], error) {
+		return h.GetClanBuilderBaseRankingsCtx(ctx, locationID, params)
+	}, params)
 }
 
 // GetLocations returns a paginated list of all available locations.
 //
 // GET /locations
 func (h *Client) GetLocations(params *PagingParams) (*PaginatedResponse[Location], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(), req, true)
+	return h.GetLocationsCtx(context.Background(), params)
+}
+
+// GetLocationsCtx is like GetLocations, but uses ctx for the request.
+func (h *Client) GetLocationsCtx(ctx context.Context, params *PagingParams) (*PaginatedResponse[Location], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[Location]](data, meta)
+}
 
-	var locations *PaginatedResponse[Location]
-	err = sonic.Unmarshal(data, &locations)
-	return locations, err
+// IterLocations is like GetLocations, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterLocations(ctx context.Context, params *PagingParams) iter.Seq2[Location, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[Location], error) {
+		return h.GetLocationsCtx(ctx, params)
+	}, params)
 }
 
 // GetClanCapitalRankings returns a paginated list of clan capital rankings for a specific location.
 //
 // GET /locations/{locationId}/rankings/capitals
 func (h *Client) GetClanCapitalRankings(locationID int, params *PagingParams) (*PaginatedResponse[ClanCapitalRanking], error) {
-	req := h.withPaging(h.withAuth(h.newDefaultRequest()), params)
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID), "rankings/capitals"), req, true)
+	return h.GetClanCapitalRankingsCtx(context.Background(), locationID, params)
+}
+
+// GetClanCapitalRankingsCtx is like GetClanCapitalRankings, but uses ctx for the request.
+func (h *Client) GetClanCapitalRankingsCtx(ctx context.Context, locationID int, params *PagingParams) (*PaginatedResponse[ClanCapitalRanking], error) {
+	req := h.withPaging(h.newDefaultRequest(), params)
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID), "rankings/capitals"), req, true)
 	if err != nil {
 		return nil, err
 	}
+	return decode[PaginatedResponse[ClanCapitalRanking]](data, meta)
+}
 
-	var rankings *PaginatedResponse[ClanCapitalRanking]
-	err = sonic.Unmarshal(data, &rankings)
-	return rankings, err
+// IterClanCapitalRankings is like GetClanCapitalRankings, but returns an iterator over the items of all pages, starting at params. See Paginate.
+func (h *Client) IterClanCapitalRankings(ctx context.Context, locationID int, params *PagingParams) iter.Seq2[ClanCapitalRanking, error] {
+	return Paginate(ctx, func(ctx context.Context, params *PagingParams) (*PaginatedResponse[ClanCapitalRanking], error) {
+		return h.GetClanCapitalRankingsCtx(ctx, locationID, params)
+	}, params)
 }
 
 // GetLocation returns information about a specific location.
 //
 // GET /locations/{locationId}
-func (h *Client) GetLocation(locationID int) (*Location, error) {
-	data, err := h.do(http.MethodGet, LocationsEndpoint.Build(strconv.Itoa(locationID)), h.withAuth(h.newDefaultRequest()), true)
+func (h *Client) GetLocation(locationID int) (*LocationResponse, error) {
+	return h.GetLocationCtx(context.Background(), locationID)
+}
+
+// GetLocationCtx is like GetLocation, but uses ctx for the request.
+func (h *Client) GetLocationCtx(ctx context.Context, locationID int) (*LocationResponse, error) {
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(LocationsEndpoint, strconv.Itoa(locationID)), h.newDefaultRequest(), true)
 	if err != nil {
 		return nil, err
 	}
-
-	var location *Location
-	err = sonic.Unmarshal(data, &location)
-	return location, err
+	return decode[LocationResponse](data, meta)
 }
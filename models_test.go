@@ -0,0 +1,89 @@
+package goclash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestModels decodes the API responses in testdata, and checks that encoding the models again yields every field of the response, so no field gets lost.
+func TestModels(t *testing.T) {
+	testModel[Player](t, "player.json")
+	testModel[PlayerVerification](t, "verify_token.json")
+	testModel[Clan](t, "clan.json")
+	testModel[PaginatedResponse[ClanMember]](t, "clan_members.json")
+	testModel[ClanWar](t, "current_war.json")
+	testModel[PaginatedResponse[ClanWarLogEntry]](t, "war_log.json")
+	testModel[ClanWarLeagueGroup](t, "cwl_group.json")
+	testModel[ClanWarLeagueWar](t, "cwl_war.json")
+	testModel[PaginatedResponse[ClanCapitalRaidSeason]](t, "capital_raid_seasons.json")
+	testModel[PaginatedResponse[Location]](t, "locations.json")
+	testModel[LocationResponse](t, "location.json")
+	testModel[PaginatedResponse[ClanRanking]](t, "clan_rankings.json")
+	testModel[PaginatedResponse[ClanBuilderBaseRanking]](t, "clan_builder_base_rankings.json")
+	testModel[PaginatedResponse[ClanCapitalRanking]](t, "clan_capital_rankings.json")
+	testModel[PaginatedResponse[PlayerRanking]](t, "player_rankings.json")
+	testModel[PaginatedResponse[PlayerBuilderBaseRanking]](t, "player_builder_base_rankings.json")
+	testModel[PaginatedResponse[League]](t, "leagues.json")
+	testModel[PaginatedResponse[LeagueSeason]](t, "league_seasons.json")
+	testModel[PaginatedResponse[PlayerRankingList]](t, "league_season_rankings.json")
+	testModel[PaginatedResponse[WarLeague]](t, "war_leagues.json")
+	testModel[PaginatedResponse[CapitalLeague]](t, "capital_leagues.json")
+	testModel[PaginatedResponse[BuilderBaseLeague]](t, "builder_base_leagues.json")
+	testModel[PaginatedResponse[Label]](t, "labels.json")
+	testModel[GoldPassSeason](t, "gold_pass_season.json")
+}
+
+func testModel[T any](t *testing.T, name string) {
+	t.Run(name, func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		model, err := decode[T](data, ResponseMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := json.Marshal(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want, got any
+		if err = json.Unmarshal(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		if err = json.Unmarshal(encoded, &got); err != nil {
+			t.Fatal(err)
+		}
+		wantFields, gotFields := make(map[string]any), make(map[string]any)
+		flattenJSON("", want, wantFields)
+		flattenJSON("", got, gotFields)
+		for path, value := range wantFields {
+			if gotValue, ok := gotFields[path]; !ok {
+				t.Errorf("%T is missing %s", model, path)
+			} else if !reflect.DeepEqual(value, gotValue) {
+				t.Errorf("%T has %s = %v, want %v", model, path, gotValue, value)
+			}
+		}
+	})
+}
+
+// flattenJSON adds the values of decoded JSON to fields, keyed by their path.
+func flattenJSON(path string, v any, fields map[string]any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			flattenJSON(path+"."+key, value, fields)
+		}
+	case []any:
+		for i, value := range v {
+			flattenJSON(fmt.Sprintf("%s[%d]", path, i), value, fields)
+		}
+	default:
+		fields[path] = v
+	}
+}
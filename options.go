@@ -0,0 +1,166 @@
+package goclash
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created by New.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient           *http.Client
+	transport            http.RoundTripper
+	timeout              time.Duration
+	baseURL              string
+	devBaseURL           string
+	ipLookupURL          string
+	userAgent            string
+	cache                *Cache
+	cacheStore           CacheStore
+	cacheLimits          *MemoryConfig
+	staleWhileRevalidate time.Duration
+	logger               *slog.Logger
+	keyPrefix            string
+	rateLimit            float64
+	rateBurst            int
+	maxRetries           int
+	maxConcurrency       int
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		baseURL:        BaseURL,
+		devBaseURL:     DevBaseURL,
+		ipLookupURL:    IPifyEndpoint,
+		userAgent:      defaultUserAgent,
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		keyPrefix:      defaultKeyPrefix,
+		maxRetries:     defaultMaxRetries,
+		maxConcurrency: defaultMaxConcurrency,
+	}
+}
+
+// WithHTTPClient sets the *http.Client used for all requests. The client is copied and never modified. If it has no cookie jar, the copy gets its own, because the developer portal session relies on cookies.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = hc
+	}
+}
+
+// WithTransport sets the http.RoundTripper used for all requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout sets the timeout for every single request. A timeout of 0 means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithBaseURL overrides the base URL of the Clash of Clans API, which defaults to BaseURL.
+func WithBaseURL(url string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = url
+	}
+}
+
+// WithDevBaseURL overrides the base URL of the developer portal, which defaults to DevBaseURL.
+func WithDevBaseURL(url string) Option {
+	return func(o *clientOptions) {
+		o.devBaseURL = url
+	}
+}
+
+// WithIPLookupURL overrides the URL used to look up the public IP address, which defaults to IPifyEndpoint. The response body must be the plain IP address.
+func WithIPLookupURL(url string) Option {
+	return func(o *clientOptions) {
+		o.ipLookupURL = url
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithCache sets the cache used by the client. Use NewCache to create one, e.g. to share it between multiple clients.
+func WithCache(cache *Cache) Option {
+	return func(o *clientOptions) {
+		o.cache = cache
+	}
+}
+
+// WithCacheStore sets the store the client caches responses in, e.g. a FileStore or RedisStore to share them between processes. It takes precedence over WithCache.
+func WithCacheStore(store CacheStore) Option {
+	return func(o *clientOptions) {
+		o.cacheStore = store
+	}
+}
+
+// WithCacheLimits limits the default in-memory cache to maxEntries values and maxBytes bytes, evicting the least recently used values first.
+// Pass 0 to leave either unlimited. It has no effect if WithCache or WithCacheStore is used.
+func WithCacheLimits(maxEntries int, maxBytes int64) Option {
+	return func(o *clientOptions) {
+		o.cacheLimits = &MemoryConfig{MaxEntries: maxEntries, MaxBytes: maxBytes}
+	}
+}
+
+// WithStaleWhileRevalidate allows returning cached responses for up to d after they expired, while they are refreshed in the background. See Client.SetStaleWhileRevalidate.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.staleWhileRevalidate = d
+	}
+}
+
+// WithLogger sets the logger the client reports IP address changes and key management to. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithKeyNamePrefix sets the name prefix of the API keys managed by the client, which defaults to "goclash". Keys created by the client are named prefix.
+//
+// The prefix acts as a namespace: the client only reuses and revokes keys whose name starts with prefix, and never touches other keys of the account.
+// Machines sharing a developer account should therefore use prefixes that don't start with each other, like "bot-a" and "bot-b", so they don't revoke each other's keys.
+func WithKeyNamePrefix(prefix string) Option {
+	return func(o *clientOptions) {
+		o.keyPrefix = prefix
+	}
+}
+
+// WithRateLimit limits the requests made with each API key to rps requests per second, allowing bursts of up to burst requests.
+// Requests exceeding the limit wait until the key is available again. By default, requests are not limited.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *clientOptions) {
+		o.rateLimit = rps
+		o.rateBurst = burst
+	}
+}
+
+// WithMaxRetries sets how often a request is retried when the API responds with http.StatusTooManyRequests or http.StatusServiceUnavailable, which defaults to 3.
+// Retries respect the Retry-After header and otherwise back off exponentially. The throttled key is paused for the same duration. Pass 0 to disable retries.
+func WithMaxRetries(n int) Option {
+	return func(o *clientOptions) {
+		o.maxRetries = n
+	}
+}
+
+// WithMaxConcurrency limits how many requests the bulk methods, like GetPlayers and StreamClans, run at the same time, which defaults to 32.
+// The limit is shared by all bulk calls of the client. Values below 1 are ignored.
+func WithMaxConcurrency(n int) Option {
+	return func(o *clientOptions) {
+		if n > 0 {
+			o.maxConcurrency = n
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package goclash
 
+import (
+	"context"
+	"iter"
+)
+
 // Paging represents the paging information returned by the API.
 type Paging struct {
 	Cursors PagingCursors `json:"cursors,omitempty"`
@@ -19,6 +24,77 @@ type PagingParams struct {
 
 // PaginatedResponse represents a paginated response from the API.
 type PaginatedResponse[T any] struct {
+	ResponseMeta `json:"-"`
+
 	Paging Paging `json:"paging,omitempty"`
 	Items  []T    `json:"items,omitempty"`
 }
+
+// PageFunc fetches a single page of a paginated endpoint, e.g. a closure around Client.GetClanWarLogCtx.
+type PageFunc[T any] func(ctx context.Context, params *PagingParams) (*PaginatedResponse[T], error)
+
+// Paginate returns an iterator over the items of all pages of a paginated endpoint. It starts at params, which may be nil, and follows the After cursors until the last page.
+// If fetching a page fails, the error is yielded and iteration stops.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T], params *PagingParams) iter.Seq2[T, error] {
+	return paginate(ctx, fetch, params, false)
+}
+
+// PaginateBackward is like Paginate, but follows the Before cursors, starting at params.Before. Items are yielded in reverse order, so the item preceding params.Before comes first.
+func PaginateBackward[T any](ctx context.Context, fetch PageFunc[T], params *PagingParams) iter.Seq2[T, error] {
+	return paginate(ctx, fetch, params, true)
+}
+
+func paginate[T any](ctx context.Context, fetch PageFunc[T], params *PagingParams, backward bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var p PagingParams
+		if params != nil {
+			p = *params
+		}
+		for {
+			page, err := fetch(ctx, &p)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			if backward {
+				for i := len(page.Items) - 1; i >= 0; i-- {
+					if !yield(page.Items[i], nil) {
+						return
+					}
+				}
+				if page.Paging.Cursors.Before == "" {
+					return
+				}
+				p.After, p.Before = "", page.Paging.Cursors.Before
+			} else {
+				for _, item := range page.Items {
+					if !yield(item, nil) {
+						return
+					}
+				}
+				if page.Paging.Cursors.After == "" {
+					return
+				}
+				p.After, p.Before = page.Paging.Cursors.After, ""
+			}
+		}
+	}
+}
+
+// FetchAll collects the items of all pages of a paginated endpoint, see Paginate. It stops after maxItems items, unless maxItems is 0 or less.
+// If fetching a page fails, the items collected so far are returned together with the error.
+func FetchAll[T any](ctx context.Context, fetch PageFunc[T], params *PagingParams, maxItems int) ([]T, error) {
+	var items []T
+	for item, err := range Paginate(ctx, fetch, params) {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+		if maxItems > 0 && len(items) >= maxItems {
+			break
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,80 @@
+package goclash
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newPagingServer serves the labels 0 to 4 in pages of two, using the label IDs as cursors.
+func newPagingServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			fmt.Sscan(after, &start)
+			start++
+		}
+		if before := r.URL.Query().Get("before"); before != "" {
+			fmt.Sscan(before, &start)
+			start -= 2
+		}
+
+		var items []string
+		for id := start; id < start+2 && id < 5; id++ {
+			items = append(items, fmt.Sprintf(`{"id":%d}`, id))
+		}
+		var after, before string
+		if start+2 < 5 {
+			after = fmt.Sprint(start + 1)
+		}
+		if start > 0 {
+			before = fmt.Sprint(start)
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, `{"items":[%s],"paging":{"cursors":{"after":%q,"before":%q}}}`, strings.Join(items, ","), after, before)
+	}))
+}
+
+func TestPaginate(t *testing.T) {
+	srv := newPagingServer(t)
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int
+	for label, err := range client.IterClanLabels(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, label.ID)
+	}
+	if want := []int{0, 1, 2, 3, 4}; !slices.Equal(ids, want) {
+		t.Fatalf("got labels %v, want %v", ids, want)
+	}
+
+	ids = ids[:0]
+	for label, err := range PaginateBackward(context.Background(), client.GetClanLabelsCtx, &PagingParams{PagingCursors: PagingCursors{Before: "4"}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, label.ID)
+	}
+	if want := []int{3, 2, 1, 0}; !slices.Equal(ids, want) {
+		t.Fatalf("got labels %v backwards, want %v", ids, want)
+	}
+
+	labels, err := FetchAll(context.Background(), client.GetClanLabelsCtx, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 3 {
+		t.Fatalf("FetchAll returned %d labels, want 3", len(labels))
+	}
+}
@@ -1,13 +1,11 @@
 package goclash
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
-	"sync"
-
-	"github.com/bytedance/sonic"
 )
 
 // PlayerBase is embedded in Player and contains the most basic information about a player. May be used as DTO.
@@ -36,6 +34,8 @@ type PlayerBase struct {
 // Player is a player returned by the API.
 type Player struct {
 	*PlayerBase
+	ResponseMeta `json:"-"`
+
 	WarPreference       string            `json:"warPreference"`
 	TownHallWeaponLevel int               `json:"townHallWeaponLevel"`
 	LegendStatistics    LegendStatistics  `json:"legendStatistics"`
@@ -118,7 +118,7 @@ type PlayerClan struct {
 
 type PlayerItemLevel struct {
 	Name               string            `json:"name"`
-	Village            string            `json:"village"`
+	Village            Village           `json:"village"`
 	Level              int               `json:"level"`
 	MaxLevel           int               `json:"maxLevel"`
 	SuperTroopIsActive bool              `json:"superTroopIsActive,omitempty"`
@@ -126,11 +126,14 @@ type PlayerItemLevel struct {
 }
 
 type LegendStatistics struct {
-	PreviousSeason   Season        `json:"previousSeason,omitempty"`
-	BestSeason       Season        `json:"bestSeason,omitempty"`
-	BestVersusSeason Season        `json:"bestVersusSeason,omitempty"`
-	CurrentSeason    CurrentSeason `json:"currentSeason,omitempty"`
-	LegendTrophies   int           `json:"legendTrophies,omitempty"`
+	PreviousSeason Season `json:"previousSeason,omitempty"`
+	BestSeason     Season `json:"bestSeason,omitempty"`
+	// Deprecated: the API no longer returns versus seasons. Use BestBuilderBaseSeason instead.
+	BestVersusSeason          Season        `json:"bestVersusSeason,omitempty"`
+	PreviousBuilderBaseSeason Season        `json:"previousBuilderBaseSeason,omitempty"`
+	BestBuilderBaseSeason     Season        `json:"bestBuilderBaseSeason,omitempty"`
+	CurrentSeason             CurrentSeason `json:"currentSeason,omitempty"`
+	LegendTrophies            int           `json:"legendTrophies,omitempty"`
 }
 
 type CurrentSeason struct {
@@ -149,7 +152,15 @@ type BuilderBaseLeague struct {
 	Name string `json:"name"`
 }
 
+// BuilderBaseLeagueResponse is the BuilderBaseLeague returned by GetBuilderBaseLeague, together with the ResponseMeta of the response.
+type BuilderBaseLeagueResponse struct {
+	ResponseMeta `json:"-"`
+	BuilderBaseLeague
+}
+
 type PlayerVerification struct {
+	ResponseMeta `json:"-"`
+
 	Tag    string `json:"tag"`
 	Token  string `json:"token"`
 	Status string `json:"status"`
@@ -160,8 +171,8 @@ type PlayerHouse struct {
 }
 
 type PlayerHouseElement struct {
-	ID   int    `json:"id"`
-	Type string `json:"type"`
+	ID   int                    `json:"id"`
+	Type PlayerHouseElementType `json:"type"`
 }
 
 func (v *PlayerVerification) IsOk() bool {
@@ -169,90 +180,72 @@ func (v *PlayerVerification) IsOk() bool {
 }
 
 const (
-	VillageHome    = "home"
-	VillageBuilder = "builderBase"
-
 	PlayerVerificationStatusOk      = "ok"
 	PlayerVerificationStatusInvalid = "invalid"
-
-	PlayerHouseElementTypeGround = "ground"
-	PlayerHouseElementTypeRoof   = "roof"
-	PlayerHouseElementTypeFoot   = "foot"
-	PlayerHouseElementTypeDeco   = "deco"
 )
 
 // GetPlayer returns information about a single player by tag.
 //
 // GET /players/{playerTag}
-func (h *Client) GetPlayer(tag string) (*Player, error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withAuth(h.newDefaultRequest())
-	data, err := h.do(http.MethodGet, PlayersEndpoint.Build(tag), req, true)
+func (h *Client) GetPlayer(tag Tag) (*Player, error) {
+	return h.GetPlayerCtx(context.Background(), tag)
+}
+
+// GetPlayerCtx is like GetPlayer, but uses ctx for the request.
+func (h *Client) GetPlayerCtx(ctx context.Context, tag Tag) (*Player, error) {
+	escaped, err := escapeTag(tag)
 	if err != nil {
 		return nil, err
 	}
-
-	var player *Player
-	err = sonic.Unmarshal(data, &player)
-	return player, err
+	req := h.newDefaultRequest()
+	data, meta, err := h.do(ctx, http.MethodGet, h.buildURL(PlayersEndpoint, escaped), req, true)
+	if err != nil {
+		return nil, err
+	}
+	return decode[Player](data, meta)
 }
 
 // GetPlayers makes use of concurrency to get multiple players simultaneously. Players that failed to be fetched will be nil in the returned slice.
-func (h *Client) GetPlayers(tags ...string) Players {
-	var wg sync.WaitGroup
-	players := make(Players, len(tags))
-	for i, tag := range tags {
-		wg.Add(1)
-		go func(i int, tag string) {
-			defer wg.Done()
-			player, _ := h.GetPlayer(tag)
-			players[i] = player
-		}(i, tag)
-	}
-	wg.Wait()
-	return players
+func (h *Client) GetPlayers(tags ...Tag) Players {
+	return h.GetPlayersCtx(context.Background(), tags...)
 }
 
-// GetPlayersWithError makes use of concurrency to get multiple players simultaneously. Unlike GetPlayers, this function returns nil and error if any of the players failed to be fetched.
-func (h *Client) GetPlayersWithError(tags ...string) (Players, error) {
-	var wg sync.WaitGroup
-	players := make(Players, len(tags))
-	errChan := make(chan error, len(tags))
-
-	for i, tag := range tags {
-		wg.Add(1)
-		go func(i int, tag string) {
-			defer wg.Done()
-			player, err := h.GetPlayer(tag)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			players[i] = player
-		}(i, tag)
-	}
-	wg.Wait()
+// GetPlayersCtx is like GetPlayers, but uses ctx for the requests.
+func (h *Client) GetPlayersCtx(ctx context.Context, tags ...Tag) Players {
+	return h.FetchPlayers(ctx, tags...).Values()
+}
 
-	if len(errChan) > 0 {
-		return nil, <-errChan
-	}
-	return players, nil
+// GetPlayersWithError makes use of concurrency to get multiple players simultaneously. Unlike GetPlayers, this function also returns an error if any of the players failed to be fetched.
+// The error joins a *TagError for each of these players, which are nil in the returned slice. Use FetchPlayers for more control over failed tags.
+func (h *Client) GetPlayersWithError(tags ...Tag) (Players, error) {
+	return h.GetPlayersWithErrorCtx(context.Background(), tags...)
+}
+
+// GetPlayersWithErrorCtx is like GetPlayersWithError, but uses ctx for the requests.
+func (h *Client) GetPlayersWithErrorCtx(ctx context.Context, tags ...Tag) (Players, error) {
+	res := h.FetchPlayers(ctx, tags...)
+	return res.Values(), res.Err()
 }
 
-// VerifyPlayer verifies a player token.
+// VerifyPlayer verifies a player token. Use a Linker to link players to the users of your application.
 //
 // POST /players/{playerTag}/verifytoken
-func (h *Client) VerifyPlayer(tag, token string) (*PlayerVerification, error) {
-	tag = TagURLSafe(CorrectTag(tag))
-	req := h.withAuth(h.newDefaultRequest()).SetBody(map[string]string{
+func (h *Client) VerifyPlayer(tag Tag, token string) (*PlayerVerification, error) {
+	return h.VerifyPlayerCtx(context.Background(), tag, token)
+}
+
+// VerifyPlayerCtx is like VerifyPlayer, but uses ctx for the request.
+func (h *Client) VerifyPlayerCtx(ctx context.Context, tag Tag, token string) (*PlayerVerification, error) {
+	escaped, err := escapeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	req := h.newDefaultRequest().SetBody(map[string]string{
 		"token": token,
 	})
-	data, err := h.do(http.MethodPost, PlayersEndpoint.Build(fmt.Sprintf("%s/verifytoken", tag)), req, false)
+	data, meta, err := h.do(ctx, http.MethodPost, h.buildURL(PlayersEndpoint, fmt.Sprintf("%s/verifytoken", escaped)), req, true)
 	if err != nil {
 		return nil, err
 	}
-
-	var verification *PlayerVerification
-	err = sonic.Unmarshal(data, &verification)
-	return verification, err
+	return decode[PlayerVerification](data, meta)
 }
@@ -0,0 +1,115 @@
+package goclash
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	minRetryDelay     = 500 * time.Millisecond
+	maxRetryDelay     = 30 * time.Second
+)
+
+// rateLimiter is a token bucket limiting the requests made with a single API key.
+//
+// Tokens may go negative, which reserves future tokens for waiting callers. A rate <= 0 means unlimited, but pauses are still honored.
+type rateLimiter struct {
+	rate        float64 // rate is the number of tokens added per second
+	burst       float64
+	tokens      float64
+	last        time.Time // last is when tokens were last refilled
+	pausedUntil time.Time
+	mu          sync.Mutex
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before it may be used.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var delay time.Duration
+	if now.Before(l.pausedUntil) {
+		delay = l.pausedUntil.Sub(now)
+		now = l.pausedUntil
+	}
+	if l.rate <= 0 {
+		return delay
+	}
+
+	if now.After(l.last) {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+	l.tokens--
+	if l.tokens < 0 {
+		delay += time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	return delay
+}
+
+// wait blocks until a token is available, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	return sleepCtx(ctx, l.reserve())
+}
+
+// pause stops handing out tokens for d, e.g. after the API responded with http.StatusTooManyRequests.
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// sleepCtx sleeps for d, returning early with the context's error if ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryableStatus reports whether a request that failed with status should be retried after a delay.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// retryDelay returns how long to wait before retrying a throttled request. It respects the Retry-After header if present,
+// and otherwise backs off exponentially, starting at minRetryDelay.
+func retryDelay(header http.Header, attempt int) time.Duration {
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return min(time.Duration(seconds)*time.Second, maxRetryDelay)
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return min(time.Until(t), maxRetryDelay)
+		}
+	}
+	return min(minRetryDelay<<attempt, maxRetryDelay)
+}
@@ -0,0 +1,36 @@
+package goclash
+
+import (
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// ResponseMeta holds caching information about a response. It is embedded in every type returned by Client methods, and only set on values returned directly by them.
+//
+// Use ExpiresAt to schedule polling: the API does not return updated data before then.
+type ResponseMeta struct {
+	FromCache bool      // FromCache is true if the response was served from cache, including responses revalidated using a conditional request.
+	FetchedAt time.Time // FetchedAt is when the response was fetched from the API, or last revalidated.
+	ExpiresAt time.Time // ExpiresAt is when the response expires. It is zero if the response must not be cached.
+}
+
+func (m *ResponseMeta) responseMeta() ResponseMeta {
+	return *m
+}
+
+func (m *ResponseMeta) setResponseMeta(meta ResponseMeta) {
+	*m = meta
+}
+
+// decode unmarshals a response body into a new T, and sets its ResponseMeta if it embeds one.
+func decode[T any](data []byte, meta ResponseMeta) (*T, error) {
+	var v *T
+	if err := sonic.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if setter, ok := any(v).(interface{ setResponseMeta(ResponseMeta) }); ok && v != nil {
+		setter.setResponseMeta(meta)
+	}
+	return v, nil
+}
@@ -0,0 +1,117 @@
+package goclash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagAlphabet contains all characters a tag consists of, in the order of their numeric value.
+const TagAlphabet = "0289PYLQGRJCUV"
+
+// maxTagID is the first numeric ID that doesn't fit into the 8 bit high and 32 bit low part used by the game.
+const maxTagID = 1 << 40
+
+// ErrInvalidTag is returned when a tag is not a valid player, clan or war tag. Methods taking a tag return it before sending any request.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// Tag is a player, clan or war tag, like "#2PP". Methods taking a Tag parse it before sending a request, so string constants can be passed as they are.
+// Use ParseTag to validate and normalize user input up front.
+type Tag string
+
+// ParseTag parses s as a tag. It accepts lowercase letters, surrounding whitespace, a missing # and the letter O in place of 0.
+// Any other character that is not in TagAlphabet, or a leading 0, results in an error wrapping ErrInvalidTag.
+func ParseTag(s string) (Tag, error) {
+	code := strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(s)), "O", "0")
+	code = strings.TrimPrefix(code, "#")
+	if code == "" {
+		return "", fmt.Errorf("%w %q: tag is empty", ErrInvalidTag, s)
+	}
+	// a leading 0 doesn't change the ID, so it would allow many spellings of the same tag, and "#0" is a placeholder of the API
+	if code[0] == '0' {
+		return "", fmt.Errorf("%w %q: tag starts with 0", ErrInvalidTag, s)
+	}
+	var id uint64
+	for _, r := range code {
+		i := strings.IndexRune(TagAlphabet, r)
+		if i < 0 {
+			return "", fmt.Errorf("%w %q: character %q is not allowed", ErrInvalidTag, s, r)
+		}
+		if id = id*uint64(len(TagAlphabet)) + uint64(i); id >= maxTagID {
+			return "", fmt.Errorf("%w %q: tag is too long", ErrInvalidTag, s)
+		}
+	}
+	return Tag("#" + code), nil
+}
+
+// MustParseTag is like ParseTag, but panics if s is not a valid tag.
+func MustParseTag(s string) Tag {
+	tag, err := ParseTag(s)
+	if err != nil {
+		panic(err)
+	}
+	return tag
+}
+
+// TagFromID returns the tag of the numeric ID made up of high and low, which is the inverse of Tag.ID. The ID 0 results in the invalid tag "#0".
+func TagFromID(high, low uint32) Tag {
+	id := uint64(low)<<8 | uint64(high&0xff)
+	var code []byte
+	for {
+		code = append(code, TagAlphabet[id%uint64(len(TagAlphabet))])
+		id /= uint64(len(TagAlphabet))
+		if id == 0 {
+			break
+		}
+	}
+	code = append(code, '#')
+	for i, j := 0, len(code)-1; i < j; i, j = i+1, j-1 {
+		code[i], code[j] = code[j], code[i]
+	}
+	return Tag(code)
+}
+
+// ID returns the numeric ID the game uses internally for the tag, split into its high and low part.
+// The tag must be valid, as returned by ParseTag.
+//
+// Credit to: https://github.com/mathsman5133/coc.py/blob/master/coc/utils.py
+func (t Tag) ID() (high, low uint32) {
+	var id uint64
+	for _, r := range strings.TrimPrefix(string(t), "#") {
+		id = id*uint64(len(TagAlphabet)) + uint64(strings.IndexRune(TagAlphabet, r))
+	}
+	return uint32(id & 0xff), uint32(id >> 8)
+}
+
+// IsValid reports whether t is a normalized tag, as returned by ParseTag.
+func (t Tag) IsValid() bool {
+	parsed, err := ParseTag(string(t))
+	return err == nil && parsed == t
+}
+
+// String returns the tag, including the leading #.
+func (t Tag) String() string {
+	return string(t)
+}
+
+// URLSafe returns the tag escaped to be used in a URL path.
+func (t Tag) URLSafe() string {
+	return TagURLSafe(string(t))
+}
+
+// escapeTag parses tag and returns it escaped to be used in a URL path, so that invalid tags never cost a request.
+func escapeTag(tag Tag) (string, error) {
+	t, err := ParseTag(string(tag))
+	if err != nil {
+		return "", err
+	}
+	return t.URLSafe(), nil
+}
+
+// normalizeTag returns the parsed tag, or tag as it is if it is invalid.
+func normalizeTag(tag Tag) Tag {
+	if t, err := ParseTag(string(tag)); err == nil {
+		return t
+	}
+	return tag
+}
@@ -0,0 +1,71 @@
+package goclash
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		input string
+		tag   Tag
+		ok    bool
+	}{
+		{"#2PP", "#2PP", true},
+		{" 2pp ", "#2PP", true},
+		{"#8QYG8CJO", "#8QYG8CJ0", true},
+		{"", "", false},
+		{"#", "", false},
+		{"#2PX", "", false},
+		{"#2P-P", "", false},
+		{"#2PPPPPPPPPPPPPP", "", false},
+		{"#0PP", "", false},
+		{"#00PP", "", false},
+		{"#0000000000000000000PP", "", false},
+		{"#0", "", false},
+		{"#O", "", false},
+	}
+	for _, tt := range tests {
+		tag, err := ParseTag(tt.input)
+		if tag != tt.tag || (err == nil) != tt.ok {
+			t.Errorf("ParseTag(%q) = %q, %v; want %q, ok %v", tt.input, tag, err, tt.tag, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("ParseTag(%q) returned %v, want ErrInvalidTag", tt.input, err)
+		}
+	}
+}
+
+func TestTagID(t *testing.T) {
+	if high, low := Tag("#2PP").ID(); high != 0 || low != 1 {
+		t.Fatalf("#2PP has ID %d, %d; want 0, 1", high, low)
+	}
+	for _, s := range []string{"#2PP", "#8QYG8CJ0", "#LRPLYJ9U2", "#VVVVVVVVVV"} {
+		tag := MustParseTag(s)
+		if got := TagFromID(tag.ID()); got != tag {
+			t.Errorf("TagFromID(%q.ID()) = %q", tag, got)
+		}
+	}
+}
+
+func TestInvalidTagSendsNoRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.GetPlayer("#NOTATAG"); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("GetPlayer returned %v, want ErrInvalidTag", err)
+	}
+	if _, err := client.GetClan("hello world"); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("GetClan returned %v, want ErrInvalidTag", err)
+	}
+}
@@ -0,0 +1,54 @@
+package goclash
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// TimeLayout is the layout of timestamps returned by the API, e.g. "20240101T120000.000Z".
+const TimeLayout = "20060102T150405.000Z"
+
+// Time is a timestamp returned by the API. It embeds time.Time, and unmarshals from and marshals to the API's TimeLayout.
+// Empty and null timestamps unmarshal to the zero Time, which marshals to an empty string.
+type Time struct {
+	time.Time
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte(`""`), nil
+	}
+	return []byte(`"` + t.UTC().Format(TimeLayout) + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		*t = Time{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("goclash: invalid timestamp %s", data)
+	}
+	parsed, err := time.Parse(TimeLayout, string(data[1:len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*t = Time{parsed}
+	return nil
+}
+
+// String returns the time in the API's TimeLayout, or an empty string for the zero Time, like MarshalJSON.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeLayout)
+}
+
+// Remaining returns the time left until t, or 0 if t has passed.
+func (t Time) Remaining() time.Duration {
+	return max(time.Until(t.Time), 0)
+}
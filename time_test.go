@@ -0,0 +1,48 @@
+package goclash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestTime(t *testing.T) {
+	var war ClanWar
+	if err := sonic.Unmarshal([]byte(`{"state":"preparation","startTime":"20240102T120000.000Z","endTime":"","preparationStartTime":null}`), &war); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC); !war.StartTime.Equal(want) {
+		t.Fatalf("got start time %v, want %v", war.StartTime, want)
+	}
+	if !war.EndTime.IsZero() || !war.PreparationStartTime.IsZero() {
+		t.Fatalf("got end time %v and preparation start time %v, want zero", war.EndTime, war.PreparationStartTime)
+	}
+
+	data, err := sonic.Marshal(war.StartTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"20240102T120000.000Z"` {
+		t.Fatalf("got %s, want the API format", data)
+	}
+	if s := war.StartTime.String(); s != "20240102T120000.000Z" {
+		t.Fatalf("got %q, want the API format", s)
+	}
+	if s := war.EndTime.String(); s != "" {
+		t.Fatalf("got %q for the zero Time, want an empty string", s)
+	}
+
+	if err = sonic.Unmarshal([]byte(`{"startTime":"2024-01-02"}`), &war); err == nil {
+		t.Fatal("got no error for an invalid timestamp")
+	}
+
+	war.State = ClanWarStatePreparation
+	war.StartTime = Time{time.Now().Add(time.Hour)}
+	if remaining := war.PreparationTimeRemaining(); remaining <= 59*time.Minute || remaining > time.Hour {
+		t.Fatalf("got %v preparation remaining, want about 1h", remaining)
+	}
+	if remaining := war.TimeRemaining(); remaining != 0 {
+		t.Fatalf("got %v remaining for a war without end time, want 0", remaining)
+	}
+}
@@ -11,7 +11,8 @@ var (
 	regexpTag = regexp.MustCompile("[^A-Z0-9]+")
 )
 
-// CorrectTag returns a valid Clash of Clans tag. It will be uppercase, have no special characters, and have a # at the beginning.
+// CorrectTag returns a Clash of Clans tag. It will be uppercase, have no special characters, and have a # at the beginning.
+// Unlike ParseTag, it never rejects a tag, even if it contains characters that are not in TagAlphabet.
 //
 // Credit to: https://github.com/mathsman5133/coc.py/blob/master/coc/utils.py
 func CorrectTag(tag string) string {
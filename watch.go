@@ -0,0 +1,131 @@
+package goclash
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval    = time.Minute
+	defaultWatchMinInterval = 5 * time.Second
+)
+
+// WatchOptions configures watchers, like Client.WatchWars. A nil *WatchOptions uses the defaults.
+//
+// Watchers poll each tag again as soon as the previous response expired, which is when the API returns updated data.
+type WatchOptions struct {
+	// Interval is the time between two polls of a tag if the response has no expiry, or polling failed. It defaults to 1 minute.
+	Interval time.Duration
+	// MinInterval is the minimum time between two polls of a tag, which defaults to 5 seconds.
+	MinInterval time.Duration
+}
+
+func (o *WatchOptions) interval() time.Duration {
+	if o == nil || o.Interval <= 0 {
+		return defaultWatchInterval
+	}
+	return o.Interval
+}
+
+func (o *WatchOptions) minInterval() time.Duration {
+	if o == nil || o.MinInterval <= 0 {
+		return defaultWatchMinInterval
+	}
+	return o.MinInterval
+}
+
+// next returns when to poll again after a response with meta was received.
+func (o *WatchOptions) next(meta ResponseMeta) time.Duration {
+	if meta.ExpiresAt.IsZero() {
+		return max(o.interval(), o.minInterval())
+	}
+	return max(time.Until(meta.ExpiresAt), o.minInterval())
+}
+
+// WatchError is emitted by watchers if polling a tag failed. The tag is polled again after WatchOptions.Interval.
+type WatchError struct {
+	Tag Tag
+	Err error
+}
+
+func (e *WatchError) Error() string {
+	return fmt.Sprintf("watching %s: %v", e.Tag, e.Err)
+}
+
+func (e *WatchError) Unwrap() error {
+	return e.Err
+}
+
+// watch polls every tag until ctx is done, and sends the events returned by diff to the returned channel, which is closed once ctx is done.
+// The first response of a tag only serves as the baseline for diff. Failed polls are sent as the event newErr returns for the *WatchError.
+func watch[T interface{ responseMeta() ResponseMeta }, E any](ctx context.Context, tags []Tag, opts *WatchOptions, fetch func(context.Context, Tag) (T, error), diff func(tag Tag, old, cur T) []E, newErr func(*WatchError) E) <-chan E {
+	events := make(chan E)
+	send := func(event E) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, tag := range uniqueTags(tags) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				last    T
+				hasLast bool
+			)
+			for {
+				wait := opts.interval()
+				cur, err := fetch(ctx, tag)
+				switch {
+				case ctx.Err() != nil:
+					return
+				case err != nil:
+					if !send(newErr(&WatchError{Tag: tag, Err: err})) {
+						return
+					}
+				default:
+					if hasLast {
+						for _, event := range diff(tag, last, cur) {
+							if !send(event) {
+								return
+							}
+						}
+					}
+					last, hasLast = cur, true
+					wait = opts.next(cur.responseMeta())
+				}
+
+				if sleepCtx(ctx, wait) != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return events
+}
+
+// uniqueTags returns the parsed tags, without duplicates. Invalid tags are kept as they are, so that polling them reports the error.
+func uniqueTags(tags []Tag) []Tag {
+	seen := make(map[Tag]bool, len(tags))
+	unique := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,163 @@
+package goclash
+
+import (
+	"context"
+	"slices"
+)
+
+// ClanEvent is an event emitted by Client.WatchClans: a *ClanSettingsChangeEvent, *ClanMemberJoinEvent, *ClanMemberLeaveEvent, *ClanMemberRenameEvent,
+// *ClanMemberRoleChangeEvent or *WatchError.
+type ClanEvent interface {
+	isClanEvent()
+}
+
+// ClanSetting is a setting of a clan, reported as changed by a ClanSettingsChangeEvent.
+type ClanSetting string
+
+const (
+	ClanSettingName                        ClanSetting = "name"
+	ClanSettingDescription                 ClanSetting = "description"
+	ClanSettingType                        ClanSetting = "type"
+	ClanSettingBadge                       ClanSetting = "badge"
+	ClanSettingLocation                    ClanSetting = "location"
+	ClanSettingChatLanguage                ClanSetting = "chatLanguage"
+	ClanSettingLabels                      ClanSetting = "labels"
+	ClanSettingWarFrequency                ClanSetting = "warFrequency"
+	ClanSettingIsWarLogPublic              ClanSetting = "isWarLogPublic"
+	ClanSettingIsFamilyFriendly            ClanSetting = "isFamilyFriendly"
+	ClanSettingRequiredTrophies            ClanSetting = "requiredTrophies"
+	ClanSettingRequiredBuilderBaseTrophies ClanSetting = "requiredBuilderBaseTrophies"
+	ClanSettingRequiredTownHallLevel       ClanSetting = "requiredTownHallLevel"
+)
+
+// ClanSettingsChangeEvent is emitted when settings of a clan changed. Compare OldClan and Clan for the old and new values.
+type ClanSettingsChangeEvent struct {
+	ClanTag Tag
+	Clan    *Clan
+	OldClan *Clan
+	Changed []ClanSetting
+}
+
+// ClanMemberJoinEvent is emitted when a player joined a clan.
+type ClanMemberJoinEvent struct {
+	ClanTag Tag
+	Clan    *Clan
+	Member  ClanMember
+}
+
+// ClanMemberLeaveEvent is emitted when a player left or was kicked from a clan. Member is the player as last seen in the clan.
+type ClanMemberLeaveEvent struct {
+	ClanTag Tag
+	Clan    *Clan
+	Member  ClanMember
+}
+
+// ClanMemberRenameEvent is emitted when a member of a clan changed their name.
+type ClanMemberRenameEvent struct {
+	ClanTag Tag
+	Clan    *Clan
+	Member  ClanMember
+	OldName string
+}
+
+// ClanMemberRoleChangeEvent is emitted when a member of a clan was promoted or demoted.
+type ClanMemberRoleChangeEvent struct {
+	ClanTag Tag
+	Clan    *Clan
+	Member  ClanMember
+	OldRole ClanRole
+	NewRole ClanRole
+}
+
+// Promoted reports whether the member was promoted, rather than demoted.
+func (e *ClanMemberRoleChangeEvent) Promoted() bool {
+	return e.NewRole.Rank() > e.OldRole.Rank()
+}
+
+func (*ClanSettingsChangeEvent) isClanEvent()   {}
+func (*ClanMemberJoinEvent) isClanEvent()       {}
+func (*ClanMemberLeaveEvent) isClanEvent()      {}
+func (*ClanMemberRenameEvent) isClanEvent()     {}
+func (*ClanMemberRoleChangeEvent) isClanEvent() {}
+func (*WatchError) isClanEvent()                {}
+
+// WatchClans polls the clans with the given tags until ctx is done, and emits a ClanEvent for every change of their settings and members.
+// The returned channel must be drained, and is closed once ctx is done. The first poll of each clan only serves as the baseline.
+//
+// Events of a single poll are emitted in the order settings change, joins, leaves, renames and role changes.
+func (h *Client) WatchClans(ctx context.Context, opts *WatchOptions, tags ...Tag) <-chan ClanEvent {
+	return watch(ctx, tags, opts, h.GetClanCtx, diffClan, func(e *WatchError) ClanEvent { return e })
+}
+
+// diffClan returns the events for the changes from old to cur.
+func diffClan(tag Tag, old, cur *Clan) []ClanEvent {
+	var events []ClanEvent
+	if changed := changedClanSettings(old, cur); len(changed) > 0 {
+		events = append(events, &ClanSettingsChangeEvent{ClanTag: tag, Clan: cur, OldClan: old, Changed: changed})
+	}
+
+	oldMembers := make(map[string]ClanMember, len(old.MemberList))
+	for _, member := range old.MemberList {
+		oldMembers[member.Tag] = member
+	}
+	curMembers := make(map[string]bool, len(cur.MemberList))
+	var renames, roleChanges []ClanEvent
+	for _, member := range cur.MemberList {
+		curMembers[member.Tag] = true
+		oldMember, ok := oldMembers[member.Tag]
+		if !ok {
+			events = append(events, &ClanMemberJoinEvent{ClanTag: tag, Clan: cur, Member: member})
+			continue
+		}
+		if oldMember.Name != member.Name {
+			renames = append(renames, &ClanMemberRenameEvent{ClanTag: tag, Clan: cur, Member: member, OldName: oldMember.Name})
+		}
+		if oldMember.Role != member.Role {
+			roleChanges = append(roleChanges, &ClanMemberRoleChangeEvent{ClanTag: tag, Clan: cur, Member: member, OldRole: oldMember.Role, NewRole: member.Role})
+		}
+	}
+	for _, member := range old.MemberList {
+		if !curMembers[member.Tag] {
+			events = append(events, &ClanMemberLeaveEvent{ClanTag: tag, Clan: cur, Member: member})
+		}
+	}
+	events = append(events, renames...)
+	return append(events, roleChanges...)
+}
+
+// changedClanSettings returns the settings that differ between old and cur.
+func changedClanSettings(old, cur *Clan) []ClanSetting {
+	labelIDs := func(labels []Label) []int {
+		ids := make([]int, len(labels))
+		for i, label := range labels {
+			ids[i] = label.ID
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	var changed []ClanSetting
+	for _, setting := range []struct {
+		setting ClanSetting
+		changed bool
+	}{
+		{ClanSettingName, old.Name != cur.Name},
+		{ClanSettingDescription, old.Description != cur.Description},
+		{ClanSettingType, old.Type != cur.Type},
+		{ClanSettingBadge, old.BadgeURLs != cur.BadgeURLs},
+		{ClanSettingLocation, old.Location.ID != cur.Location.ID},
+		{ClanSettingChatLanguage, old.ChatLanguage.ID != cur.ChatLanguage.ID},
+		{ClanSettingLabels, !slices.Equal(labelIDs(old.Labels), labelIDs(cur.Labels))},
+		{ClanSettingWarFrequency, old.WarFrequency != cur.WarFrequency},
+		{ClanSettingIsWarLogPublic, old.IsWarLogPublic != cur.IsWarLogPublic},
+		{ClanSettingIsFamilyFriendly, old.IsFamilyFriendly != cur.IsFamilyFriendly},
+		{ClanSettingRequiredTrophies, old.RequiredTrophies != cur.RequiredTrophies},
+		{ClanSettingRequiredBuilderBaseTrophies, old.RequiredBuilderBaseTrophies != cur.RequiredBuilderBaseTrophies},
+		{ClanSettingRequiredTownHallLevel, old.RequiredTownHallLevel != cur.RequiredTownHallLevel},
+	} {
+		if setting.changed {
+			changed = append(changed, setting.setting)
+		}
+	}
+	return changed
+}
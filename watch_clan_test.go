@@ -0,0 +1,61 @@
+package goclash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffClan(t *testing.T) {
+	old := &Clan{
+		Description:      "old",
+		RequiredTrophies: 1000,
+		Labels:           []Label{{ID: 1}, {ID: 2}},
+		MemberList: []ClanMember{
+			{Tag: "#A", Name: "leader", Role: ClanRoleLeader},
+			{Tag: "#B", Name: "b", Role: ClanRoleMember},
+			{Tag: "#C", Name: "c", Role: ClanRoleCoLeader},
+			{Tag: "#D", Name: "d", Role: ClanRoleMember},
+		},
+	}
+	cur := &Clan{
+		Description:      "new",
+		RequiredTrophies: 1000,
+		Labels:           []Label{{ID: 2}, {ID: 1}},
+		MemberList: []ClanMember{
+			{Tag: "#A", Name: "leader", Role: ClanRoleLeader},
+			{Tag: "#B", Name: "b2", Role: ClanRoleAdmin},
+			{Tag: "#C", Name: "c", Role: ClanRoleAdmin},
+			{Tag: "#E", Name: "e", Role: ClanRoleMember},
+		},
+	}
+
+	var got []string
+	for _, event := range diffClan("#2PP", old, cur) {
+		switch e := event.(type) {
+		case *ClanSettingsChangeEvent:
+			got = append(got, fmt.Sprintf("settings %v", e.Changed))
+		case *ClanMemberJoinEvent:
+			got = append(got, "join "+e.Member.Name)
+		case *ClanMemberLeaveEvent:
+			got = append(got, "leave "+e.Member.Name)
+		case *ClanMemberRenameEvent:
+			got = append(got, fmt.Sprintf("rename %s->%s", e.OldName, e.Member.Name))
+		case *ClanMemberRoleChangeEvent:
+			got = append(got, fmt.Sprintf("role %s %s->%s promoted=%t", e.Member.Name, e.OldRole, e.NewRole, e.Promoted()))
+		default:
+			got = append(got, fmt.Sprintf("unexpected %T", e))
+		}
+	}
+
+	want := []string{
+		"settings [description]",
+		"join e",
+		"leave d",
+		"rename b->b2",
+		"role b2 member->admin promoted=true",
+		"role c coLeader->admin promoted=false",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got events\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+package goclash
+
+import (
+	"context"
+	"iter"
+)
+
+// PlayerEvent is an event emitted by Client.WatchPlayers: a *PlayerNameChangeEvent, *PlayerTownHallChangeEvent, *PlayerTrophiesChangeEvent, *PlayerDonationsChangeEvent,
+// *PlayerClanChangeEvent, *PlayerRoleChangeEvent, *PlayerUpgradeEvent, *PlayerAchievementChangeEvent or *WatchError.
+type PlayerEvent interface {
+	isPlayerEvent()
+}
+
+// PlayerNameChangeEvent is emitted when a player changed their name.
+type PlayerNameChangeEvent struct {
+	PlayerTag Tag
+	Player    *Player
+	OldName   string
+	NewName   string
+}
+
+// PlayerTownHallChangeEvent is emitted when a player upgraded their town hall.
+type PlayerTownHallChangeEvent struct {
+	PlayerTag Tag
+	Player    *Player
+	OldLevel  int
+	NewLevel  int
+}
+
+// PlayerTrophiesChangeEvent is emitted when a player's trophies change.
+type PlayerTrophiesChangeEvent struct {
+	PlayerTag   Tag
+	Player      *Player
+	OldTrophies int
+	NewTrophies int
+}
+
+// PlayerDonationsChangeEvent is emitted when a player's donations or received donations change. Both are reset at the start of every season, so they may decrease.
+type PlayerDonationsChangeEvent struct {
+	PlayerTag            Tag
+	Player               *Player
+	OldDonations         int
+	NewDonations         int
+	OldDonationsReceived int
+	NewDonationsReceived int
+}
+
+// PlayerClanChangeEvent is emitted when a player joined, left or switched a clan. The clan is zero if the player is not in a clan.
+type PlayerClanChangeEvent struct {
+	PlayerTag Tag
+	Player    *Player
+	OldClan   PlayerClan
+	NewClan   PlayerClan
+}
+
+// PlayerRoleChangeEvent is emitted when a player's role in their clan changes, without switching the clan.
+type PlayerRoleChangeEvent struct {
+	PlayerTag Tag
+	Player    *Player
+	OldRole   ClanRole
+	NewRole   ClanRole
+}
+
+// PlayerItemKind is the kind of PlayerItemLevel upgraded in a PlayerUpgradeEvent.
+type PlayerItemKind string
+
+const (
+	PlayerItemKindTroop         PlayerItemKind = "troop"
+	PlayerItemKindHero          PlayerItemKind = "hero"
+	PlayerItemKindSpell         PlayerItemKind = "spell"
+	PlayerItemKindHeroEquipment PlayerItemKind = "heroEquipment"
+)
+
+// PlayerUpgradeEvent is emitted when a player upgraded or unlocked a troop, hero, spell or hero equipment. OldLevel is 0 if the item was unlocked.
+type PlayerUpgradeEvent struct {
+	PlayerTag Tag
+	Player    *Player
+	Kind      PlayerItemKind
+	Item      PlayerItemLevel
+	OldLevel  int
+	NewLevel  int
+}
+
+// PlayerAchievementChangeEvent is emitted when the value or stars of one of a player's achievements change.
+type PlayerAchievementChangeEvent struct {
+	PlayerTag      Tag
+	Player         *Player
+	OldAchievement Achievement
+	NewAchievement Achievement
+}
+
+func (*PlayerNameChangeEvent) isPlayerEvent()        {}
+func (*PlayerTownHallChangeEvent) isPlayerEvent()    {}
+func (*PlayerTrophiesChangeEvent) isPlayerEvent()    {}
+func (*PlayerDonationsChangeEvent) isPlayerEvent()   {}
+func (*PlayerClanChangeEvent) isPlayerEvent()        {}
+func (*PlayerRoleChangeEvent) isPlayerEvent()        {}
+func (*PlayerUpgradeEvent) isPlayerEvent()           {}
+func (*PlayerAchievementChangeEvent) isPlayerEvent() {}
+func (*WatchError) isPlayerEvent()                   {}
+
+// WatchPlayers polls the players with the given tags until ctx is done, and emits a PlayerEvent for every change.
+// The returned channel must be drained, and is closed once ctx is done. The first poll of each player only serves as the baseline.
+func (h *Client) WatchPlayers(ctx context.Context, opts *WatchOptions, tags ...Tag) <-chan PlayerEvent {
+	return watch(ctx, tags, opts, h.GetPlayerCtx, diffPlayer, func(e *WatchError) PlayerEvent { return e })
+}
+
+// diffPlayer returns the events for the changes from old to cur.
+func diffPlayer(tag Tag, old, cur *Player) []PlayerEvent {
+	var events []PlayerEvent
+	if old.Name != cur.Name {
+		events = append(events, &PlayerNameChangeEvent{PlayerTag: tag, Player: cur, OldName: old.Name, NewName: cur.Name})
+	}
+	if old.TownHallLevel != cur.TownHallLevel {
+		events = append(events, &PlayerTownHallChangeEvent{PlayerTag: tag, Player: cur, OldLevel: old.TownHallLevel, NewLevel: cur.TownHallLevel})
+	}
+	if old.Trophies != cur.Trophies {
+		events = append(events, &PlayerTrophiesChangeEvent{PlayerTag: tag, Player: cur, OldTrophies: old.Trophies, NewTrophies: cur.Trophies})
+	}
+	if old.Donations != cur.Donations || old.DonationsReceived != cur.DonationsReceived {
+		events = append(events, &PlayerDonationsChangeEvent{
+			PlayerTag:            tag,
+			Player:               cur,
+			OldDonations:         old.Donations,
+			NewDonations:         cur.Donations,
+			OldDonationsReceived: old.DonationsReceived,
+			NewDonationsReceived: cur.DonationsReceived,
+		})
+	}
+	if old.Clan.Tag != cur.Clan.Tag {
+		events = append(events, &PlayerClanChangeEvent{PlayerTag: tag, Player: cur, OldClan: old.Clan, NewClan: cur.Clan})
+	} else if old.Role != cur.Role {
+		events = append(events, &PlayerRoleChangeEvent{PlayerTag: tag, Player: cur, OldRole: old.Role, NewRole: cur.Role})
+	}
+
+	for _, items := range []struct {
+		kind     PlayerItemKind
+		old, cur []PlayerItemLevel
+	}{
+		{PlayerItemKindTroop, old.Troops, cur.Troops},
+		{PlayerItemKindHero, old.Heroes, cur.Heroes},
+		{PlayerItemKindSpell, old.Spells, cur.Spells},
+		{PlayerItemKindHeroEquipment, old.HeroEquipment, cur.HeroEquipment},
+	} {
+		levels := make(map[[2]string]int, len(items.old))
+		for _, item := range items.old {
+			levels[[2]string{item.Name, string(item.Village)}] = item.Level
+		}
+		for _, item := range items.cur {
+			if oldLevel := levels[[2]string{item.Name, string(item.Village)}]; oldLevel != item.Level {
+				events = append(events, &PlayerUpgradeEvent{PlayerTag: tag, Player: cur, Kind: items.kind, Item: item, OldLevel: oldLevel, NewLevel: item.Level})
+			}
+		}
+	}
+
+	// achievements are matched by name, and by occurrence for the few sharing a name, because the info changes with the stars
+	achievements := make(map[achievementKey]Achievement, len(old.Achievements))
+	for key, achievement := range keyAchievements(old.Achievements) {
+		achievements[key] = achievement
+	}
+	for key, achievement := range keyAchievements(cur.Achievements) {
+		oldAchievement, ok := achievements[key]
+		if ok && (oldAchievement.Value != achievement.Value || oldAchievement.Stars != achievement.Stars) {
+			events = append(events, &PlayerAchievementChangeEvent{PlayerTag: tag, Player: cur, OldAchievement: oldAchievement, NewAchievement: achievement})
+		}
+	}
+	return events
+}
+
+type achievementKey struct {
+	name       string
+	occurrence int
+}
+
+// keyAchievements returns an iterator over achievements, keyed by their name and occurrence.
+func keyAchievements(achievements []Achievement) iter.Seq2[achievementKey, Achievement] {
+	return func(yield func(achievementKey, Achievement) bool) {
+		occurrences := make(map[string]int)
+		for _, achievement := range achievements {
+			key := achievementKey{name: achievement.Name, occurrence: occurrences[achievement.Name]}
+			occurrences[achievement.Name]++
+			if !yield(key, achievement) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package goclash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffPlayer(t *testing.T) {
+	old := &Player{
+		PlayerBase: &PlayerBase{Name: "old", TownHallLevel: 14, Trophies: 5000, Donations: 10, Clan: PlayerClan{Tag: "#2PP"}, Role: ClanRoleMember},
+		Troops:     []PlayerItemLevel{{Name: "Barbarian", Village: "home", Level: 10}, {Name: "Barbarian", Village: "builderBase", Level: 5}},
+		Heroes:     []PlayerItemLevel{{Name: "Barbarian King", Village: "home", Level: 80}},
+		Achievements: []Achievement{
+			{Name: "Gold Grab", Value: 100, Info: "Steal 100 gold"},
+			{Name: "Keep Your Account Safe!", Value: 0},
+			{Name: "Keep Your Account Safe!", Value: 0},
+		},
+	}
+	cur := &Player{
+		PlayerBase: &PlayerBase{Name: "new", TownHallLevel: 14, Trophies: 5000, Donations: 20, Clan: PlayerClan{Tag: "#2PP"}, Role: ClanRoleAdmin},
+		Troops:     []PlayerItemLevel{{Name: "Barbarian", Village: "home", Level: 11}, {Name: "Barbarian", Village: "builderBase", Level: 5}},
+		Heroes:     []PlayerItemLevel{{Name: "Barbarian King", Village: "home", Level: 80}},
+		Spells:     []PlayerItemLevel{{Name: "Rage Spell", Village: "home", Level: 1}},
+		Achievements: []Achievement{
+			{Name: "Gold Grab", Value: 200, Info: "Steal 1000 gold"},
+			{Name: "Keep Your Account Safe!", Value: 0},
+			{Name: "Keep Your Account Safe!", Value: 1},
+		},
+	}
+
+	var got []string
+	for _, event := range diffPlayer("#2QQ", old, cur) {
+		switch e := event.(type) {
+		case *PlayerNameChangeEvent:
+			got = append(got, fmt.Sprintf("name %s->%s", e.OldName, e.NewName))
+		case *PlayerDonationsChangeEvent:
+			got = append(got, fmt.Sprintf("donations %d->%d", e.OldDonations, e.NewDonations))
+		case *PlayerRoleChangeEvent:
+			got = append(got, fmt.Sprintf("role %s->%s", e.OldRole, e.NewRole))
+		case *PlayerUpgradeEvent:
+			got = append(got, fmt.Sprintf("%s %s %d->%d", e.Kind, e.Item.Name, e.OldLevel, e.NewLevel))
+		case *PlayerAchievementChangeEvent:
+			got = append(got, fmt.Sprintf("achievement %s %d->%d", e.NewAchievement.Name, e.OldAchievement.Value, e.NewAchievement.Value))
+		default:
+			got = append(got, fmt.Sprintf("unexpected %T", e))
+		}
+	}
+
+	want := []string{
+		"name old->new",
+		"donations 10->20",
+		"role member->admin",
+		"troop Barbarian 10->11",
+		"spell Rage Spell 0->1",
+		"achievement Gold Grab 100->200",
+		"achievement Keep Your Account Safe! 0->1",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got events\n%q\nwant\n%q", got, want)
+	}
+}
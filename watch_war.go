@@ -0,0 +1,169 @@
+package goclash
+
+import (
+	"context"
+	"slices"
+)
+
+// WarEvent is an event emitted by Client.WatchWars: a *WarStateChangeEvent, *WarAttackEvent, *WarScoreChangeEvent, *WarEndEvent or *WatchError.
+type WarEvent interface {
+	isWarEvent()
+}
+
+// WarStateChangeEvent is emitted when the state of a clan's war changes, e.g. from ClanWarStatePreparation to ClanWarStateInWar.
+// It is also emitted when a new war starts, even if the state is the same as in the previous war.
+type WarStateChangeEvent struct {
+	ClanTag  Tag
+	War      *ClanWar
+	OldState ClanWarState
+	NewState ClanWarState
+}
+
+// WarAttackEvent is emitted for every new attack in a clan's war, made either by the clan or by its opponent.
+type WarAttackEvent struct {
+	ClanTag  Tag
+	War      *ClanWar
+	Attack   ClanWarAttack
+	Attacker ClanWarMember
+	Defender ClanWarMember
+	Defense  bool // Defense is true if the attack was made by the opponent.
+}
+
+// WarScore is the score of one side of a war.
+type WarScore struct {
+	Stars                 int
+	DestructionPercentage float64
+	Attacks               int
+}
+
+// WarScoreChangeEvent is emitted when the stars or destruction of either side of a clan's war change.
+type WarScoreChangeEvent struct {
+	ClanTag     Tag
+	War         *ClanWar
+	OldClan     WarScore
+	Clan        WarScore
+	OldOpponent WarScore
+	Opponent    WarScore
+}
+
+// WarEndEvent is emitted when a clan's war ended.
+type WarEndEvent struct {
+	ClanTag Tag
+	War     *ClanWar
+	Result  ClanWarResult // Result is the result from the clan's perspective.
+}
+
+func (*WarStateChangeEvent) isWarEvent() {}
+func (*WarAttackEvent) isWarEvent()      {}
+func (*WarScoreChangeEvent) isWarEvent() {}
+func (*WarEndEvent) isWarEvent()         {}
+func (*WatchError) isWarEvent()          {}
+
+// WatchWars polls the current wars of the clans with the given tags until ctx is done, and emits a WarEvent for every change.
+// The returned channel must be drained, and is closed once ctx is done.
+//
+// The first poll of each clan only serves as the baseline, so attacks made before watching started are not emitted.
+// Events of a single poll are emitted in the order state change, attacks (ordered by ClanWarAttack.Order), score change, end.
+func (h *Client) WatchWars(ctx context.Context, opts *WatchOptions, tags ...Tag) <-chan WarEvent {
+	return watch(ctx, tags, opts, h.GetCurrentClanWarCtx, diffWar, func(e *WatchError) WarEvent { return e })
+}
+
+// Result returns the result of the war from the clan's perspective, comparing stars first and destruction second. It is only final once the war ended.
+func (w *ClanWar) Result() ClanWarResult {
+	return warResult(&w.Clan, &w.Opponent)
+}
+
+// warResult returns the result of a war from the perspective of clan.
+func warResult(clan, opponent *WarClan) ClanWarResult {
+	switch {
+	case clan.Stars > opponent.Stars:
+		return ClanWarResultWin
+	case clan.Stars < opponent.Stars:
+		return ClanWarResultLose
+	case clan.DestructionPercentage > opponent.DestructionPercentage:
+		return ClanWarResultWin
+	case clan.DestructionPercentage < opponent.DestructionPercentage:
+		return ClanWarResultLose
+	default:
+		return ClanWarResultTie
+	}
+}
+
+// isSameWar reports whether w and other are the same war, possibly in different states.
+func (w *ClanWar) isSameWar(other *ClanWar) bool {
+	return w.PreparationStartTime.Equal(other.PreparationStartTime.Time) && w.Opponent.Tag == other.Opponent.Tag
+}
+
+// score returns the score of one side of a war.
+func (c *WarClan) score() WarScore {
+	return WarScore{Stars: c.Stars, DestructionPercentage: c.DestructionPercentage, Attacks: c.Attacks}
+}
+
+// diffWar returns the events for the changes from old to cur. If cur is a different war, old is treated as an empty war.
+func diffWar(tag Tag, old, cur *ClanWar) []WarEvent {
+	var events []WarEvent
+	sameWar := old.isSameWar(cur)
+	if !sameWar || old.State != cur.State {
+		events = append(events, &WarStateChangeEvent{ClanTag: tag, War: cur, OldState: old.State, NewState: cur.State})
+	}
+	if !sameWar {
+		old = &ClanWar{}
+	}
+
+	seen := make(map[int]bool)
+	for _, attack := range warAttacks(old) {
+		seen[attack.Order] = true
+	}
+	members := make(map[string]ClanWarMember)
+	for _, side := range []*WarClan{&cur.Clan, &cur.Opponent} {
+		for _, member := range side.Members {
+			members[member.Tag] = member
+		}
+	}
+	opponents := make(map[string]bool, len(cur.Opponent.Members))
+	for _, member := range cur.Opponent.Members {
+		opponents[member.Tag] = true
+	}
+	for _, attack := range warAttacks(cur) {
+		if seen[attack.Order] {
+			continue
+		}
+		events = append(events, &WarAttackEvent{
+			ClanTag:  tag,
+			War:      cur,
+			Attack:   attack,
+			Attacker: members[attack.AttackerTag],
+			Defender: members[attack.DefenderTag],
+			Defense:  opponents[attack.AttackerTag],
+		})
+	}
+
+	if old.Clan.score() != cur.Clan.score() || old.Opponent.score() != cur.Opponent.score() {
+		events = append(events, &WarScoreChangeEvent{
+			ClanTag:     tag,
+			War:         cur,
+			OldClan:     old.Clan.score(),
+			Clan:        cur.Clan.score(),
+			OldOpponent: old.Opponent.score(),
+			Opponent:    cur.Opponent.score(),
+		})
+	}
+	if cur.State == ClanWarStateWarEnded && old.State != ClanWarStateWarEnded {
+		events = append(events, &WarEndEvent{ClanTag: tag, War: cur, Result: cur.Result()})
+	}
+	return events
+}
+
+// warAttacks returns the attacks of both sides of a war, ordered by ClanWarAttack.Order.
+func warAttacks(war *ClanWar) []ClanWarAttack {
+	var attacks []ClanWarAttack
+	for _, side := range []*WarClan{&war.Clan, &war.Opponent} {
+		for _, member := range side.Members {
+			attacks = append(attacks, member.Attacks...)
+		}
+	}
+	slices.SortFunc(attacks, func(a, b ClanWarAttack) int {
+		return a.Order - b.Order
+	})
+	return attacks
+}
@@ -0,0 +1,78 @@
+package goclash
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchWars(t *testing.T) {
+	const (
+		member   = `{"tag":"#C1","name":"clan member","mapPosition":1%s}`
+		opponent = `{"tag":"#E1","name":"enemy","mapPosition":1%s}`
+		attack   = `,"attacks":[{"order":%d,"attackerTag":"%s","defenderTag":"%s","stars":%d,"destructionPercentage":%d}]`
+	)
+	war := func(state string, clanStars, enemyStars int, memberAttacks, enemyAttacks string) string {
+		return fmt.Sprintf(`{"state":%q,"teamSize":1,"preparationStartTime":"20240101T000000.000Z",`+
+			`"clan":{"tag":"#2PP","stars":%d,"members":[%s]},"opponent":{"tag":"#2QQ","stars":%d,"members":[%s]}}`,
+			state, clanStars, fmt.Sprintf(member, memberAttacks), enemyStars, fmt.Sprintf(opponent, enemyAttacks))
+	}
+	responses := []string{
+		war("preparation", 0, 0, "", ""),
+		war("inWar", 0, 0, "", ""),
+		war("inWar", 0, 2, "", fmt.Sprintf(attack, 1, "#E1", "#C1", 2, 60)),
+		war("warEnded", 3, 2, fmt.Sprintf(attack, 2, "#C1", "#E1", 3, 100), fmt.Sprintf(attack, 1, "#E1", "#C1", 2, 60)),
+	}
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(polls.Add(1)) - 1
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprint(w, responses[min(n, len(responses)-1)])
+	}))
+	defer srv.Close()
+
+	client, err := NewWithKeys([]string{"key"}, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := client.WatchWars(ctx, &WatchOptions{MinInterval: time.Millisecond}, "#2PP")
+
+	var got []string
+	for event := range events {
+		switch e := event.(type) {
+		case *WarStateChangeEvent:
+			got = append(got, fmt.Sprintf("state %s->%s", e.OldState, e.NewState))
+		case *WarAttackEvent:
+			got = append(got, fmt.Sprintf("attack %d %s->%s defense=%t", e.Attack.Order, e.Attacker.Name, e.Defender.Name, e.Defense))
+		case *WarScoreChangeEvent:
+			got = append(got, fmt.Sprintf("score %d-%d", e.Clan.Stars, e.Opponent.Stars))
+		case *WarEndEvent:
+			got = append(got, "end "+e.Result.String())
+			cancel()
+		case *WatchError:
+			t.Fatal(e)
+		}
+	}
+
+	want := []string{
+		"state preparation->inWar",
+		"attack 1 enemy->clan member defense=true",
+		"score 0-2",
+		"state inWar->warEnded",
+		"attack 2 clan member->enemy defense=false",
+		"score 3-2",
+		"end win",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got events\n%q\nwant\n%q", got, want)
+	}
+}